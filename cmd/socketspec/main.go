@@ -0,0 +1,18 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/vtv-us/kahoot-backend/internal/services"
+)
+
+// socketspec writes the spec of the socket protocol to stdout.
+func main() {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(services.NewProtocolSpec()); err != nil {
+		log.Fatal("cannot write socket spec:", err)
+	}
+}
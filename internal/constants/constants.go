@@ -15,10 +15,54 @@ const (
 
 	Cookies_ACCESS_TOKEN = "cookieAccess"
 
-	SocketParticipantStatus_ACTIVE = "active"
-	SocketParticipantStatus_LEFT   = "left"
+	SocketParticipantStatus_ACTIVE  = "active"
+	SocketParticipantStatus_LEFT    = "left"
+	SocketParticipantStatus_PENDING = "pending"
+
+	RoomError_FULL   = "room_full"
+	RoomError_BANNED = "banned"
+
+	TeamMode_AUTO = "auto"
+	TeamMode_PICK = "pick"
+
+	SessionStore_MEMORY   = "memory"
+	SessionStore_POSTGRES = "postgres"
+
+	Broadcaster_LOCAL    = "local"
+	Broadcaster_POSTGRES = "postgres"
 
 	QuestionType_MULTIPLE_CHOICE = "multiple-choice"
 	QuestionType_PARAGRAPH       = "paragraph"
 	QuestionType_HEADING         = "heading"
+	QuestionType_MULTI_SELECT    = "multi-select"
+	QuestionType_SCALE           = "scale"
+	QuestionType_RANKING         = "ranking"
+
+	UserQuestionStatus_PENDING  = "pending"
+	UserQuestionStatus_APPROVED = "approved"
+	UserQuestionStatus_REJECTED = "rejected"
+
+	UserQuestionSort_TOP      = "top"
+	UserQuestionSort_NEWEST   = "newest"
+	UserQuestionSort_ANSWERED = "answered"
+
+	ChatFilter_MASK   = "mask"
+	ChatFilter_REJECT = "reject"
+
+	SocketError_RATE_LIMITED     = "rate_limited"
+	SocketError_INVALID_ARGUMENT = "invalid_argument"
+	SocketError_UNAUTHENTICATED  = "unauthenticated"
+	SocketError_FORBIDDEN        = "forbidden"
+	SocketError_NOT_IN_ROOM      = "not_in_room"
+	SocketError_ROOM_NOT_FOUND   = "room_not_found"
+	SocketError_NOT_RUNNING      = "not_running"
+	SocketError_NO_QUESTION      = "no_question"
+	SocketError_QUESTION_CLOSED  = "question_closed"
+	SocketError_ANSWERS_LOCKED   = "answers_locked"
+	SocketError_MUTED            = "muted"
+	SocketError_BLOCKED_WORDS    = "blocked_words"
+	SocketError_FAILED           = "failed"
+
+	ScaleRange_FIVE = "1-5"
+	ScaleRange_TEN  = "0-10"
 )
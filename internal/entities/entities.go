@@ -6,6 +6,7 @@ package entities
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -16,15 +17,31 @@ type Answer struct {
 	RawAnswer  string    `json:"raw_answer"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	IsCorrect  bool      `json:"is_correct"`
+}
+
+type AnswerSelection struct {
+	SessionID   string    `json:"session_id"`
+	Username    string    `json:"username"`
+	SlideID     string    `json:"slide_id"`
+	QuestionID  string    `json:"question_id"`
+	AnswerID    string    `json:"answer_id"`
+	Position    int32     `json:"position"`
+	Value       int32     `json:"value"`
+	CreatedAt   time.Time `json:"created_at"`
+	Participant string    `json:"participant"`
 }
 
 type AnswerHistory struct {
-	Username   string    `json:"username"`
-	SlideID    string    `json:"slide_id"`
-	QuestionID string    `json:"question_id"`
-	AnswerID   string    `json:"answer_id"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	Username    string    `json:"username"`
+	SlideID     string    `json:"slide_id"`
+	QuestionID  string    `json:"question_id"`
+	AnswerID    string    `json:"answer_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Points      int32     `json:"points"`
+	SessionID   string    `json:"session_id"`
+	Participant string    `json:"participant"`
 }
 
 type ChatMsg struct {
@@ -33,6 +50,16 @@ type ChatMsg struct {
 	Username  string    `json:"username"`
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
+	SessionID string    `json:"session_id"`
+	Deleted   bool      `json:"deleted"`
+	ParentID  string    `json:"parent_id"`
+}
+
+type ChatReaction struct {
+	MsgID     string    `json:"msg_id"`
+	Reactor   string    `json:"reactor"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Collab struct {
@@ -49,6 +76,44 @@ type Group struct {
 	Description string    `json:"description"`
 }
 
+type LiveEvent struct {
+	ID        int64           `json:"id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type LivePin struct {
+	Pin       string    `json:"pin"`
+	RoomID    string    `json:"room_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LiveRoom struct {
+	RoomID    string          `json:"room_id"`
+	Data      json.RawMessage `json:"data"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ParticipantKick struct {
+	ID            int64     `json:"id"`
+	SessionID     string    `json:"session_id"`
+	Username      string    `json:"username"`
+	UserID        string    `json:"user_id"`
+	ParticipantID string    `json:"participant_id"`
+	Banned        bool      `json:"banned"`
+	KickedBy      string    `json:"kicked_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PresentationSession struct {
+	ID        string       `json:"id"`
+	SlideID   string       `json:"slide_id"`
+	Host      string       `json:"host"`
+	GroupID   string       `json:"group_id"`
+	StartedAt time.Time    `json:"started_at"`
+	EndedAt   sql.NullTime `json:"ended_at"`
+}
+
 type Question struct {
 	ID              string    `json:"id"`
 	SlideID         string    `json:"slide_id"`
@@ -59,6 +124,22 @@ type Question struct {
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 	Type            string    `json:"type"`
+	TimeLimit       int32     `json:"time_limit"`
+}
+
+type ReactionTotal struct {
+	SessionID  string `json:"session_id"`
+	QuestionID string `json:"question_id"`
+	Emoji      string `json:"emoji"`
+	Count      int32  `json:"count"`
+}
+
+type SessionTeamMember struct {
+	SessionID   string    `json:"session_id"`
+	Username    string    `json:"username"`
+	Team        string    `json:"team"`
+	CreatedAt   time.Time `json:"created_at"`
+	Participant string    `json:"participant"`
 }
 
 type Slide struct {
@@ -70,6 +151,17 @@ type Slide struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type TextAnswer struct {
+	SessionID   string    `json:"session_id"`
+	QuestionID  string    `json:"question_id"`
+	Username    string    `json:"username"`
+	SlideID     string    `json:"slide_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Participant string    `json:"participant"`
+}
+
 type User struct {
 	UserID       string         `json:"user_id"`
 	Email        string         `json:"email"`
@@ -99,4 +191,7 @@ type UserQuestion struct {
 	Votes      int32     `json:"votes"`
 	Answered   bool      `json:"answered"`
 	CreatedAt  time.Time `json:"created_at"`
+	SessionID  string    `json:"session_id"`
+	Status     string    `json:"status"`
+	Anonymous  bool      `json:"anonymous"`
 }
@@ -48,6 +48,7 @@ INSERT INTO "answer" (
     question_id,
     index,
     raw_answer,
+    is_correct,
     created_at,
     updated_at
 ) VALUES (
@@ -55,10 +56,11 @@ INSERT INTO "answer" (
     $2,
     $3,
     $4,
+    $5,
     now(),
     now()
 )
-RETURNING id, question_id, index, raw_answer, created_at, updated_at
+RETURNING id, question_id, index, raw_answer, created_at, updated_at, is_correct
 `
 
 type CreateAnswerParams struct {
@@ -66,6 +68,7 @@ type CreateAnswerParams struct {
 	QuestionID string `json:"question_id"`
 	Index      int16  `json:"index"`
 	RawAnswer  string `json:"raw_answer"`
+	IsCorrect  bool   `json:"is_correct"`
 }
 
 func (q *Queries) CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Answer, error) {
@@ -74,6 +77,7 @@ func (q *Queries) CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Ans
 		arg.QuestionID,
 		arg.Index,
 		arg.RawAnswer,
+		arg.IsCorrect,
 	)
 	var i Answer
 	err := row.Scan(
@@ -83,6 +87,7 @@ func (q *Queries) CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Ans
 		&i.RawAnswer,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsCorrect,
 	)
 	return i, err
 }
@@ -117,7 +122,7 @@ func (q *Queries) DeleteAnswersBySlide(ctx context.Context, slideID string) erro
 }
 
 const getAnswer = `-- name: GetAnswer :one
-SELECT id, question_id, index, raw_answer, created_at, updated_at FROM "answer" WHERE id = $1
+SELECT id, question_id, index, raw_answer, created_at, updated_at, is_correct FROM "answer" WHERE id = $1
 `
 
 func (q *Queries) GetAnswer(ctx context.Context, id string) (Answer, error) {
@@ -130,12 +135,13 @@ func (q *Queries) GetAnswer(ctx context.Context, id string) (Answer, error) {
 		&i.RawAnswer,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsCorrect,
 	)
 	return i, err
 }
 
 const getAnswerByQuestionAndIndex = `-- name: GetAnswerByQuestionAndIndex :one
-SELECT id, question_id, index, raw_answer, created_at, updated_at FROM "answer" WHERE question_id = $1 AND index = $2
+SELECT id, question_id, index, raw_answer, created_at, updated_at, is_correct FROM "answer" WHERE question_id = $1 AND index = $2
 `
 
 type GetAnswerByQuestionAndIndexParams struct {
@@ -153,12 +159,13 @@ func (q *Queries) GetAnswerByQuestionAndIndex(ctx context.Context, arg GetAnswer
 		&i.RawAnswer,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsCorrect,
 	)
 	return i, err
 }
 
 const getAnswersByQuestion = `-- name: GetAnswersByQuestion :many
-SELECT id, question_id, index, raw_answer, created_at, updated_at FROM "answer" WHERE question_id = $1
+SELECT id, question_id, index, raw_answer, created_at, updated_at, is_correct FROM "answer" WHERE question_id = $1
 ORDER BY index ASC
 `
 
@@ -178,6 +185,7 @@ func (q *Queries) GetAnswersByQuestion(ctx context.Context, questionID string) (
 			&i.RawAnswer,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsCorrect,
 		); err != nil {
 			return nil, err
 		}
@@ -196,19 +204,26 @@ const updateAnswer = `-- name: UpdateAnswer :one
 UPDATE "answer" SET
     index = $2,
     raw_answer = $3,
+    is_correct = $4,
     updated_at = now()
 WHERE id = $1
-RETURNING id, question_id, index, raw_answer, created_at, updated_at
+RETURNING id, question_id, index, raw_answer, created_at, updated_at, is_correct
 `
 
 type UpdateAnswerParams struct {
 	ID        string `json:"id"`
 	Index     int16  `json:"index"`
 	RawAnswer string `json:"raw_answer"`
+	IsCorrect bool   `json:"is_correct"`
 }
 
 func (q *Queries) UpdateAnswer(ctx context.Context, arg UpdateAnswerParams) (Answer, error) {
-	row := q.db.QueryRowContext(ctx, updateAnswer, arg.ID, arg.Index, arg.RawAnswer)
+	row := q.db.QueryRowContext(ctx, updateAnswer,
+		arg.ID,
+		arg.Index,
+		arg.RawAnswer,
+		arg.IsCorrect,
+	)
 	var i Answer
 	err := row.Scan(
 		&i.ID,
@@ -217,6 +232,7 @@ func (q *Queries) UpdateAnswer(ctx context.Context, arg UpdateAnswerParams) (Ans
 		&i.RawAnswer,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsCorrect,
 	)
 	return i, err
 }
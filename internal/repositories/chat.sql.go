@@ -7,10 +7,112 @@ package repositories
 
 import (
 	"context"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const countChatReplies = `-- name: CountChatReplies :many
+SELECT parent_id, count(*) AS count FROM "chat_msg"
+WHERE parent_id = ANY($1::text[])
+GROUP BY parent_id
+`
+
+type CountChatRepliesRow struct {
+	ParentID string `json:"parent_id"`
+	Count    int64  `json:"count"`
+}
+
+func (q *Queries) CountChatReplies(ctx context.Context, msgIds []string) ([]CountChatRepliesRow, error) {
+	rows, err := q.db.QueryContext(ctx, countChatReplies, pq.Array(msgIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountChatRepliesRow{}
+	for rows.Next() {
+		var i CountChatRepliesRow
+		if err := rows.Scan(&i.ParentID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteChatMsg = `-- name: DeleteChatMsg :one
+UPDATE "chat_msg"
+SET deleted = true
+WHERE id = $1 AND session_id = $2
+RETURNING id, slide_id, username, content, created_at, session_id, deleted, parent_id
+`
+
+type DeleteChatMsgParams struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+}
+
+func (q *Queries) DeleteChatMsg(ctx context.Context, arg DeleteChatMsgParams) (ChatMsg, error) {
+	row := q.db.QueryRowContext(ctx, deleteChatMsg, arg.ID, arg.SessionID)
+	var i ChatMsg
+	err := row.Scan(
+		&i.ID,
+		&i.SlideID,
+		&i.Username,
+		&i.Content,
+		&i.CreatedAt,
+		&i.SessionID,
+		&i.Deleted,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const getChatBySession = `-- name: GetChatBySession :many
+SELECT id, slide_id, username, content, created_at, session_id, deleted, parent_id FROM "chat_msg" WHERE session_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetChatBySession(ctx context.Context, sessionID string) ([]ChatMsg, error) {
+	rows, err := q.db.QueryContext(ctx, getChatBySession, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChatMsg{}
+	for rows.Next() {
+		var i ChatMsg
+		if err := rows.Scan(
+			&i.ID,
+			&i.SlideID,
+			&i.Username,
+			&i.Content,
+			&i.CreatedAt,
+			&i.SessionID,
+			&i.Deleted,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChatBySlide = `-- name: GetChatBySlide :many
-SELECT id, slide_id, username, content, created_at FROM "chat_msg" WHERE slide_id = $1
+SELECT id, slide_id, username, content, created_at, session_id, deleted, parent_id FROM "chat_msg" WHERE slide_id = $1
 ORDER BY created_at ASC
 `
 
@@ -29,6 +131,128 @@ func (q *Queries) GetChatBySlide(ctx context.Context, slideID string) ([]ChatMsg
 			&i.Username,
 			&i.Content,
 			&i.CreatedAt,
+			&i.SessionID,
+			&i.Deleted,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChatMsg = `-- name: GetChatMsg :one
+SELECT id, slide_id, username, content, created_at, session_id, deleted, parent_id FROM "chat_msg" WHERE id = $1 AND session_id = $2
+`
+
+type GetChatMsgParams struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+}
+
+func (q *Queries) GetChatMsg(ctx context.Context, arg GetChatMsgParams) (ChatMsg, error) {
+	row := q.db.QueryRowContext(ctx, getChatMsg, arg.ID, arg.SessionID)
+	var i ChatMsg
+	err := row.Scan(
+		&i.ID,
+		&i.SlideID,
+		&i.Username,
+		&i.Content,
+		&i.CreatedAt,
+		&i.SessionID,
+		&i.Deleted,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const listChatPage = `-- name: ListChatPage :many
+SELECT id, slide_id, username, content, created_at, session_id, deleted, parent_id FROM "chat_msg"
+WHERE session_id = $1 AND parent_id = ''
+AND (created_at, id) < ($2::timestamptz, $3::text)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListChatPageParams struct {
+	SessionID  string    `json:"session_id"`
+	BeforeTime time.Time `json:"before_time"`
+	BeforeID   string    `json:"before_id"`
+	Limit      int32     `json:"limit"`
+}
+
+func (q *Queries) ListChatPage(ctx context.Context, arg ListChatPageParams) ([]ChatMsg, error) {
+	rows, err := q.db.QueryContext(ctx, listChatPage,
+		arg.SessionID,
+		arg.BeforeTime,
+		arg.BeforeID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChatMsg{}
+	for rows.Next() {
+		var i ChatMsg
+		if err := rows.Scan(
+			&i.ID,
+			&i.SlideID,
+			&i.Username,
+			&i.Content,
+			&i.CreatedAt,
+			&i.SessionID,
+			&i.Deleted,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChatReplies = `-- name: ListChatReplies :many
+SELECT id, slide_id, username, content, created_at, session_id, deleted, parent_id FROM "chat_msg" WHERE session_id = $1 AND parent_id = $2
+ORDER BY created_at ASC, id ASC
+`
+
+type ListChatRepliesParams struct {
+	SessionID string `json:"session_id"`
+	ParentID  string `json:"parent_id"`
+}
+
+func (q *Queries) ListChatReplies(ctx context.Context, arg ListChatRepliesParams) ([]ChatMsg, error) {
+	rows, err := q.db.QueryContext(ctx, listChatReplies, arg.SessionID, arg.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChatMsg{}
+	for rows.Next() {
+		var i ChatMsg
+		if err := rows.Scan(
+			&i.ID,
+			&i.SlideID,
+			&i.Username,
+			&i.Content,
+			&i.CreatedAt,
+			&i.SessionID,
+			&i.Deleted,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -49,22 +273,28 @@ INSERT INTO "chat_msg" (
     slide_id,
     username,
     content,
+    session_id,
+    parent_id,
     created_at
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
+    $5,
+    $6,
     now()
 )
-RETURNING id, slide_id, username, content, created_at
+RETURNING id, slide_id, username, content, created_at, session_id, deleted, parent_id
 `
 
 type SaveChatParams struct {
-	ID       string `json:"id"`
-	SlideID  string `json:"slide_id"`
-	Username string `json:"username"`
-	Content  string `json:"content"`
+	ID        string `json:"id"`
+	SlideID   string `json:"slide_id"`
+	Username  string `json:"username"`
+	Content   string `json:"content"`
+	SessionID string `json:"session_id"`
+	ParentID  string `json:"parent_id"`
 }
 
 func (q *Queries) SaveChat(ctx context.Context, arg SaveChatParams) (ChatMsg, error) {
@@ -73,6 +303,8 @@ func (q *Queries) SaveChat(ctx context.Context, arg SaveChatParams) (ChatMsg, er
 		arg.SlideID,
 		arg.Username,
 		arg.Content,
+		arg.SessionID,
+		arg.ParentID,
 	)
 	var i ChatMsg
 	err := row.Scan(
@@ -81,6 +313,9 @@ func (q *Queries) SaveChat(ctx context.Context, arg SaveChatParams) (ChatMsg, er
 		&i.Username,
 		&i.Content,
 		&i.CreatedAt,
+		&i.SessionID,
+		&i.Deleted,
+		&i.ParentID,
 	)
 	return i, err
 }
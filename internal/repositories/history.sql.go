@@ -12,11 +12,16 @@ import (
 const countAnswerByQuestionID = `-- name: CountAnswerByQuestionID :many
 SELECT slide_id, question_id, answer_id, count(*) as count
 FROM "answer_history"
-WHERE question_id = $1
+WHERE session_id = $1 AND question_id = $2
 GROUP BY slide_id, question_id, answer_id
 ORDER BY count DESC
 `
 
+type CountAnswerByQuestionIDParams struct {
+	SessionID  string `json:"session_id"`
+	QuestionID string `json:"question_id"`
+}
+
 type CountAnswerByQuestionIDRow struct {
 	SlideID    string `json:"slide_id"`
 	QuestionID string `json:"question_id"`
@@ -24,8 +29,8 @@ type CountAnswerByQuestionIDRow struct {
 	Count      int64  `json:"count"`
 }
 
-func (q *Queries) CountAnswerByQuestionID(ctx context.Context, questionID string) ([]CountAnswerByQuestionIDRow, error) {
-	rows, err := q.db.QueryContext(ctx, countAnswerByQuestionID, questionID)
+func (q *Queries) CountAnswerByQuestionID(ctx context.Context, arg CountAnswerByQuestionIDParams) ([]CountAnswerByQuestionIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, countAnswerByQuestionID, arg.SessionID, arg.QuestionID)
 	if err != nil {
 		return nil, err
 	}
@@ -53,19 +58,19 @@ func (q *Queries) CountAnswerByQuestionID(ctx context.Context, questionID string
 }
 
 const getAnswerHistory = `-- name: GetAnswerHistory :one
-SELECT username, slide_id, question_id, answer_id, created_at, updated_at
+SELECT username, slide_id, question_id, answer_id, created_at, updated_at, points, session_id, participant
 FROM "answer_history"
-WHERE username = $1 AND slide_id = $2 AND question_id = $3
+WHERE session_id = $1 AND participant = $2 AND question_id = $3
 `
 
 type GetAnswerHistoryParams struct {
-	Username   string `json:"username"`
-	SlideID    string `json:"slide_id"`
-	QuestionID string `json:"question_id"`
+	SessionID   string `json:"session_id"`
+	Participant string `json:"participant"`
+	QuestionID  string `json:"question_id"`
 }
 
 func (q *Queries) GetAnswerHistory(ctx context.Context, arg GetAnswerHistoryParams) (AnswerHistory, error) {
-	row := q.db.QueryRowContext(ctx, getAnswerHistory, arg.Username, arg.SlideID, arg.QuestionID)
+	row := q.db.QueryRowContext(ctx, getAnswerHistory, arg.SessionID, arg.Participant, arg.QuestionID)
 	var i AnswerHistory
 	err := row.Scan(
 		&i.Username,
@@ -74,12 +79,52 @@ func (q *Queries) GetAnswerHistory(ctx context.Context, arg GetAnswerHistoryPara
 		&i.AnswerID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Points,
+		&i.SessionID,
+		&i.Participant,
 	)
 	return i, err
 }
 
+const getLeaderboardBySessionID = `-- name: GetLeaderboardBySessionID :many
+SELECT participant, CAST(MAX(username) AS text) AS username, CAST(SUM(points) AS integer) AS score
+FROM "answer_history"
+WHERE session_id = $1
+GROUP BY participant
+ORDER BY score DESC, username ASC
+`
+
+type GetLeaderboardBySessionIDRow struct {
+	Participant string `json:"participant"`
+	Username    string `json:"username"`
+	Score       int32  `json:"score"`
+}
+
+func (q *Queries) GetLeaderboardBySessionID(ctx context.Context, sessionID string) ([]GetLeaderboardBySessionIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardBySessionID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetLeaderboardBySessionIDRow{}
+	for rows.Next() {
+		var i GetLeaderboardBySessionIDRow
+		if err := rows.Scan(&i.Participant, &i.Username, &i.Score); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAnswerHistoryByAnswerID = `-- name: ListAnswerHistoryByAnswerID :many
-SELECT username, slide_id, question_id, answer_id, created_at, updated_at
+SELECT username, slide_id, question_id, answer_id, created_at, updated_at, points, session_id, participant
 FROM "answer_history"
 WHERE answer_id = $1
 ORDER BY updated_at DESC
@@ -101,6 +146,9 @@ func (q *Queries) ListAnswerHistoryByAnswerID(ctx context.Context, answerID stri
 			&i.AnswerID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Points,
+			&i.SessionID,
+			&i.Participant,
 		); err != nil {
 			return nil, err
 		}
@@ -116,14 +164,59 @@ func (q *Queries) ListAnswerHistoryByAnswerID(ctx context.Context, answerID stri
 }
 
 const listAnswerHistoryByQuestionID = `-- name: ListAnswerHistoryByQuestionID :many
-SELECT username, slide_id, question_id, answer_id, created_at, updated_at
+SELECT username, slide_id, question_id, answer_id, created_at, updated_at, points, session_id, participant
+FROM "answer_history"
+WHERE session_id = $1 AND question_id = $2
+ORDER BY updated_at DESC
+`
+
+type ListAnswerHistoryByQuestionIDParams struct {
+	SessionID  string `json:"session_id"`
+	QuestionID string `json:"question_id"`
+}
+
+func (q *Queries) ListAnswerHistoryByQuestionID(ctx context.Context, arg ListAnswerHistoryByQuestionIDParams) ([]AnswerHistory, error) {
+	rows, err := q.db.QueryContext(ctx, listAnswerHistoryByQuestionID, arg.SessionID, arg.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AnswerHistory{}
+	for rows.Next() {
+		var i AnswerHistory
+		if err := rows.Scan(
+			&i.Username,
+			&i.SlideID,
+			&i.QuestionID,
+			&i.AnswerID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Points,
+			&i.SessionID,
+			&i.Participant,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAnswerHistoryBySessionID = `-- name: ListAnswerHistoryBySessionID :many
+SELECT username, slide_id, question_id, answer_id, created_at, updated_at, points, session_id, participant
 FROM "answer_history"
-WHERE question_id = $1
+WHERE session_id = $1
 ORDER BY updated_at DESC
 `
 
-func (q *Queries) ListAnswerHistoryByQuestionID(ctx context.Context, questionID string) ([]AnswerHistory, error) {
-	rows, err := q.db.QueryContext(ctx, listAnswerHistoryByQuestionID, questionID)
+func (q *Queries) ListAnswerHistoryBySessionID(ctx context.Context, sessionID string) ([]AnswerHistory, error) {
+	rows, err := q.db.QueryContext(ctx, listAnswerHistoryBySessionID, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +231,9 @@ func (q *Queries) ListAnswerHistoryByQuestionID(ctx context.Context, questionID
 			&i.AnswerID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Points,
+			&i.SessionID,
+			&i.Participant,
 		); err != nil {
 			return nil, err
 		}
@@ -153,7 +249,7 @@ func (q *Queries) ListAnswerHistoryByQuestionID(ctx context.Context, questionID
 }
 
 const listAnswerHistoryBySlideID = `-- name: ListAnswerHistoryBySlideID :many
-SELECT username, slide_id, question_id, answer_id, created_at, updated_at
+SELECT username, slide_id, question_id, answer_id, created_at, updated_at, points, session_id, participant
 FROM "answer_history"
 WHERE slide_id = $1
 ORDER BY updated_at DESC
@@ -175,6 +271,9 @@ func (q *Queries) ListAnswerHistoryBySlideID(ctx context.Context, slideID string
 			&i.AnswerID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Points,
+			&i.SessionID,
+			&i.Participant,
 		); err != nil {
 			return nil, err
 		}
@@ -191,31 +290,41 @@ func (q *Queries) ListAnswerHistoryBySlideID(ctx context.Context, slideID string
 
 const upsertAnswerHistory = `-- name: UpsertAnswerHistory :one
 INSERT INTO "answer_history" (
+    "session_id",
+    "participant",
     "username",
     "slide_id",
     "question_id",
-    "answer_id"
+    "answer_id",
+    "points"
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6, $7
 ) ON CONFLICT ON CONSTRAINT "answer_history_pkey" DO UPDATE SET
-    "answer_id" = $4,
+    "answer_id" = $6,
+    "points" = $7,
     "updated_at" = now()
-RETURNING username, slide_id, question_id, answer_id, created_at, updated_at
+RETURNING username, slide_id, question_id, answer_id, created_at, updated_at, points, session_id, participant
 `
 
 type UpsertAnswerHistoryParams struct {
-	Username   string `json:"username"`
-	SlideID    string `json:"slide_id"`
-	QuestionID string `json:"question_id"`
-	AnswerID   string `json:"answer_id"`
+	SessionID   string `json:"session_id"`
+	Participant string `json:"participant"`
+	Username    string `json:"username"`
+	SlideID     string `json:"slide_id"`
+	QuestionID  string `json:"question_id"`
+	AnswerID    string `json:"answer_id"`
+	Points      int32  `json:"points"`
 }
 
 func (q *Queries) UpsertAnswerHistory(ctx context.Context, arg UpsertAnswerHistoryParams) (AnswerHistory, error) {
 	row := q.db.QueryRowContext(ctx, upsertAnswerHistory,
+		arg.SessionID,
+		arg.Participant,
 		arg.Username,
 		arg.SlideID,
 		arg.QuestionID,
 		arg.AnswerID,
+		arg.Points,
 	)
 	var i AnswerHistory
 	err := row.Scan(
@@ -225,6 +334,9 @@ func (q *Queries) UpsertAnswerHistory(ctx context.Context, arg UpsertAnswerHisto
 		&i.AnswerID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Points,
+		&i.SessionID,
+		&i.Participant,
 	)
 	return i, err
 }
@@ -39,3 +39,43 @@ type UserQuestion struct {
 type ChatMsg struct {
 	entities.ChatMsg
 }
+
+type LiveRoom struct {
+	entities.LiveRoom
+}
+
+type LiveEvent struct {
+	entities.LiveEvent
+}
+
+type LivePin struct {
+	entities.LivePin
+}
+
+type PresentationSession struct {
+	entities.PresentationSession
+}
+
+type ParticipantKick struct {
+	entities.ParticipantKick
+}
+
+type SessionTeamMember struct {
+	entities.SessionTeamMember
+}
+
+type TextAnswer struct {
+	entities.TextAnswer
+}
+
+type AnswerSelection struct {
+	entities.AnswerSelection
+}
+
+type ChatReaction struct {
+	entities.ChatReaction
+}
+
+type ReactionTotal struct {
+	entities.ReactionTotal
+}
@@ -6,10 +6,13 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 )
 
 type Querier interface {
 	AddCollab(ctx context.Context, arg AddCollabParams) error
+	AddReactionTotal(ctx context.Context, arg AddReactionTotalParams) error
 	AddMemberToGroup(ctx context.Context, arg AddMemberToGroupParams) error
 	// Check if the user has permission to access the answer or collaborator
 	// of the slide that the answer belongs to.
@@ -18,55 +21,102 @@ type Querier interface {
 	CheckQuestionPermission(ctx context.Context, arg CheckQuestionPermissionParams) (bool, error)
 	CheckSlidePermission(ctx context.Context, arg CheckSlidePermissionParams) (bool, error)
 	CheckUserInGroup(ctx context.Context, arg CheckUserInGroupParams) (bool, error)
-	CountAnswerByQuestionID(ctx context.Context, questionID string) ([]CountAnswerByQuestionIDRow, error)
+	CountAnswerByQuestionID(ctx context.Context, arg CountAnswerByQuestionIDParams) ([]CountAnswerByQuestionIDRow, error)
+	CountChatReactions(ctx context.Context, arg CountChatReactionsParams) ([]CountChatReactionsRow, error)
+	CountChatReplies(ctx context.Context, msgIds []string) ([]CountChatRepliesRow, error)
 	CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Answer, error)
+	CreateAnswerSelection(ctx context.Context, arg CreateAnswerSelectionParams) error
+	CreateChatReaction(ctx context.Context, arg CreateChatReactionParams) (int64, error)
 	CreateGroup(ctx context.Context, arg CreateGroupParams) (Group, error)
+	CreateLiveEvent(ctx context.Context, payload json.RawMessage) (int64, error)
+	CreateLivePin(ctx context.Context, arg CreateLivePinParams) (int64, error)
+	CreateLiveRoomIfNotExists(ctx context.Context, roomID string) error
+	CreateParticipantKick(ctx context.Context, arg CreateParticipantKickParams) (ParticipantKick, error)
+	CreatePresentationSession(ctx context.Context, arg CreatePresentationSessionParams) (PresentationSession, error)
 	CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error)
 	CreateSlide(ctx context.Context, arg CreateSlideParams) (Slide, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserQuestionVote(ctx context.Context, arg CreateUserQuestionVoteParams) (int64, error)
 	DeleteAnswer(ctx context.Context, id string) error
+	DeleteAnswerSelections(ctx context.Context, arg DeleteAnswerSelectionsParams) error
 	DeleteAnswersByQuestion(ctx context.Context, questionID string) error
 	DeleteAnswersBySlide(ctx context.Context, slideID string) error
+	DeleteChatMsg(ctx context.Context, arg DeleteChatMsgParams) (ChatMsg, error)
+	DeleteChatReaction(ctx context.Context, arg DeleteChatReactionParams) (int64, error)
 	DeleteGroup(ctx context.Context, groupID string) error
+	DeleteLiveEventsBefore(ctx context.Context, createdAt time.Time) error
+	DeleteLivePinByRoom(ctx context.Context, roomID string) error
+	DeleteLiveRoom(ctx context.Context, roomID string) error
 	DeleteQuestion(ctx context.Context, id string) error
 	DeleteQuestionsBySlide(ctx context.Context, slideID string) error
+	DeleteSessionTeamMembersNotIn(ctx context.Context, arg DeleteSessionTeamMembersNotInParams) error
 	DeleteSlide(ctx context.Context, id string) error
 	DeleteUser(ctx context.Context, email string) error
+	DeleteUserQuestionVote(ctx context.Context, arg DeleteUserQuestionVoteParams) (int64, error)
+	EndPresentationSessionsBySlide(ctx context.Context, slideID string) error
 	GetAnswer(ctx context.Context, id string) (Answer, error)
 	GetAnswerByQuestionAndIndex(ctx context.Context, arg GetAnswerByQuestionAndIndexParams) (Answer, error)
 	GetAnswerHistory(ctx context.Context, arg GetAnswerHistoryParams) (AnswerHistory, error)
 	GetAnswersByQuestion(ctx context.Context, questionID string) ([]Answer, error)
+	GetChatBySession(ctx context.Context, sessionID string) ([]ChatMsg, error)
 	GetChatBySlide(ctx context.Context, slideID string) ([]ChatMsg, error)
+	GetChatMsg(ctx context.Context, arg GetChatMsgParams) (ChatMsg, error)
 	GetGroup(ctx context.Context, groupID string) (Group, error)
 	GetGroupByUser(ctx context.Context, userID string) ([]GetGroupByUserRow, error)
+	GetLeaderboardBySessionID(ctx context.Context, sessionID string) ([]GetLeaderboardBySessionIDRow, error)
+	GetLiveEvent(ctx context.Context, id int64) (LiveEvent, error)
+	GetLivePin(ctx context.Context, pin string) (LivePin, error)
+	GetLivePinByRoom(ctx context.Context, roomID string) (LivePin, error)
+	GetLiveRoom(ctx context.Context, roomID string) (LiveRoom, error)
+	GetLiveRoomForUpdate(ctx context.Context, roomID string) (LiveRoom, error)
+	GetLiveRoomIDByGroup(ctx context.Context, groupID string) (string, error)
 	GetOwnerOfQuestion(ctx context.Context, id string) (string, error)
+	GetPresentationSession(ctx context.Context, id string) (PresentationSession, error)
 	GetQuestion(ctx context.Context, id string) (Question, error)
 	GetQuestionBySlideAndIndex(ctx context.Context, arg GetQuestionBySlideAndIndexParams) (Question, error)
 	GetQuestionsBySlide(ctx context.Context, slideID string) ([]Question, error)
 	GetRoleInGroup(ctx context.Context, arg GetRoleInGroupParams) (string, error)
 	GetSlide(ctx context.Context, id string) (Slide, error)
 	GetSlidesByOwner(ctx context.Context, owner string) ([]Slide, error)
+	GetTeamLeaderboardBySessionID(ctx context.Context, sessionID string) ([]GetTeamLeaderboardBySessionIDRow, error)
 	GetUser(ctx context.Context, userID string) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserGroup(ctx context.Context, arg GetUserGroupParams) (UserGroup, error)
 	GetUserQuestion(ctx context.Context, questionID string) (UserQuestion, error)
+	ListActiveLiveRoomIDs(ctx context.Context) ([]string, error)
 	ListAnswerHistoryByAnswerID(ctx context.Context, answerID string) ([]AnswerHistory, error)
-	ListAnswerHistoryByQuestionID(ctx context.Context, questionID string) ([]AnswerHistory, error)
+	ListAnswerHistoryByQuestionID(ctx context.Context, arg ListAnswerHistoryByQuestionIDParams) ([]AnswerHistory, error)
+	ListAnswerHistoryBySessionID(ctx context.Context, sessionID string) ([]AnswerHistory, error)
 	ListAnswerHistoryBySlideID(ctx context.Context, slideID string) ([]AnswerHistory, error)
+	ListAnswerSelectionsByQuestion(ctx context.Context, arg ListAnswerSelectionsByQuestionParams) ([]AnswerSelection, error)
+	ListAnswerSelectionsBySession(ctx context.Context, sessionID string) ([]AnswerSelection, error)
+	ListChatPage(ctx context.Context, arg ListChatPageParams) ([]ChatMsg, error)
+	ListChatReplies(ctx context.Context, arg ListChatRepliesParams) ([]ChatMsg, error)
 	ListCollab(ctx context.Context, userID string) ([]Slide, error)
 	ListCollabBySlide(ctx context.Context, slideID string) ([]User, error)
 	ListEmailInGroup(ctx context.Context, groupID string) ([]string, error)
 	ListGroupJoined(ctx context.Context, userID string) ([]ListGroupJoinedRow, error)
 	ListGroupOwned(ctx context.Context, userID string) ([]ListGroupOwnedRow, error)
+	ListLiveRoomIDsBySID(ctx context.Context, sid string) ([]string, error)
 	ListMemberInGroup(ctx context.Context, groupID string) ([]ListMemberInGroupRow, error)
+	ListParticipantKicksBySession(ctx context.Context, sessionID string) ([]ParticipantKick, error)
+	ListPresentationSessionsBySlide(ctx context.Context, slideID string) ([]PresentationSession, error)
+	ListReactionTotalsBySession(ctx context.Context, sessionID string) ([]ReactionTotal, error)
+	ListSessionTeamMembers(ctx context.Context, sessionID string) ([]SessionTeamMember, error)
+	ListTextAnswersByQuestion(ctx context.Context, arg ListTextAnswersByQuestionParams) ([]TextAnswer, error)
+	ListTextAnswersBySession(ctx context.Context, sessionID string) ([]TextAnswer, error)
 	ListUser(ctx context.Context, arg ListUserParams) ([]User, error)
 	ListUserQuestion(ctx context.Context, slideID string) ([]UserQuestion, error)
+	ListUserQuestionBySession(ctx context.Context, sessionID string) ([]UserQuestion, error)
+	ListVotedUserQuestionIDs(ctx context.Context, arg ListVotedUserQuestionIDsParams) ([]string, error)
+	NotifyLiveEvent(ctx context.Context, payload string) error
 	RemoveCollab(ctx context.Context, arg RemoveCollabParams) error
 	RemoveMemberFromGroup(ctx context.Context, arg RemoveMemberFromGroupParams) error
 	SaveChat(ctx context.Context, arg SaveChatParams) (ChatMsg, error)
 	ToggleUserQuestionAnswered(ctx context.Context, questionID string) (UserQuestion, error)
 	UpdateAnswer(ctx context.Context, arg UpdateAnswerParams) (Answer, error)
 	UpdateAvatarUrl(ctx context.Context, arg UpdateAvatarUrlParams) (User, error)
+	UpdateLiveRoom(ctx context.Context, arg UpdateLiveRoomParams) error
 	UpdateMemberRole(ctx context.Context, arg UpdateMemberRoleParams) error
 	UpdateMemberStatus(ctx context.Context, arg UpdateMemberStatusParams) error
 	UpdatePassword(ctx context.Context, arg UpdatePasswordParams) (User, error)
@@ -75,10 +125,14 @@ type Querier interface {
 	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error)
 	UpdateSlide(ctx context.Context, arg UpdateSlideParams) (Slide, error)
 	UpdateSocialID(ctx context.Context, arg UpdateSocialIDParams) (User, error)
+	UpdateUserQuestionContent(ctx context.Context, arg UpdateUserQuestionContentParams) (UserQuestion, error)
+	UpdateUserQuestionStatus(ctx context.Context, arg UpdateUserQuestionStatusParams) (UserQuestion, error)
+	UpdateUserQuestionVotes(ctx context.Context, questionID string) (UserQuestion, error)
 	UpdateVerifiedCode(ctx context.Context, arg UpdateVerifiedCodeParams) (User, error)
 	UpsertAnswerHistory(ctx context.Context, arg UpsertAnswerHistoryParams) (AnswerHistory, error)
+	UpsertSessionTeamMember(ctx context.Context, arg UpsertSessionTeamMemberParams) error
+	UpsertTextAnswer(ctx context.Context, arg UpsertTextAnswerParams) (TextAnswer, error)
 	UpsertUserQuestion(ctx context.Context, arg UpsertUserQuestionParams) (UserQuestion, error)
-	UpvoteUserQuestion(ctx context.Context, questionID string) (UserQuestion, error)
 	Verify(ctx context.Context, email string) (User, error)
 }
 
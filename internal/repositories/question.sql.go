@@ -48,6 +48,7 @@ INSERT INTO "question" (
     meta,
     long_description,
     type,
+    time_limit,
     created_at,
     updated_at
 ) VALUES (
@@ -58,10 +59,11 @@ INSERT INTO "question" (
     $5,
     $6,
     $7,
+    $8,
     now(),
     now()
 )
-RETURNING id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type
+RETURNING id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type, time_limit
 `
 
 type CreateQuestionParams struct {
@@ -72,6 +74,7 @@ type CreateQuestionParams struct {
 	Meta            string `json:"meta"`
 	LongDescription string `json:"long_description"`
 	Type            string `json:"type"`
+	TimeLimit       int32  `json:"time_limit"`
 }
 
 func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error) {
@@ -83,6 +86,7 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		arg.Meta,
 		arg.LongDescription,
 		arg.Type,
+		arg.TimeLimit,
 	)
 	var i Question
 	err := row.Scan(
@@ -95,6 +99,7 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Type,
+		&i.TimeLimit,
 	)
 	return i, err
 }
@@ -131,7 +136,7 @@ func (q *Queries) GetOwnerOfQuestion(ctx context.Context, id string) (string, er
 }
 
 const getQuestion = `-- name: GetQuestion :one
-SELECT id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type FROM "question" WHERE id = $1
+SELECT id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type, time_limit FROM "question" WHERE id = $1
 `
 
 func (q *Queries) GetQuestion(ctx context.Context, id string) (Question, error) {
@@ -147,12 +152,13 @@ func (q *Queries) GetQuestion(ctx context.Context, id string) (Question, error)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Type,
+		&i.TimeLimit,
 	)
 	return i, err
 }
 
 const getQuestionBySlideAndIndex = `-- name: GetQuestionBySlideAndIndex :one
-SELECT id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type FROM "question" WHERE slide_id = $1 AND index = $2
+SELECT id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type, time_limit FROM "question" WHERE slide_id = $1 AND index = $2
 `
 
 type GetQuestionBySlideAndIndexParams struct {
@@ -173,12 +179,13 @@ func (q *Queries) GetQuestionBySlideAndIndex(ctx context.Context, arg GetQuestio
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Type,
+		&i.TimeLimit,
 	)
 	return i, err
 }
 
 const getQuestionsBySlide = `-- name: GetQuestionsBySlide :many
-SELECT id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type FROM "question" WHERE slide_id = $1
+SELECT id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type, time_limit FROM "question" WHERE slide_id = $1
 ORDER BY index ASC
 `
 
@@ -201,6 +208,7 @@ func (q *Queries) GetQuestionsBySlide(ctx context.Context, slideID string) ([]Qu
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Type,
+			&i.TimeLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -222,9 +230,10 @@ UPDATE "question" SET
     long_description = $4,
     index = $5,
     type = $6,
+    time_limit = $7,
     updated_at = now()
 WHERE id = $1
-RETURNING id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type
+RETURNING id, slide_id, index, raw_question, meta, long_description, created_at, updated_at, type, time_limit
 `
 
 type UpdateQuestionParams struct {
@@ -234,6 +243,7 @@ type UpdateQuestionParams struct {
 	LongDescription string `json:"long_description"`
 	Index           int16  `json:"index"`
 	Type            string `json:"type"`
+	TimeLimit       int32  `json:"time_limit"`
 }
 
 func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error) {
@@ -244,6 +254,7 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		arg.LongDescription,
 		arg.Index,
 		arg.Type,
+		arg.TimeLimit,
 	)
 	var i Question
 	err := row.Scan(
@@ -256,6 +267,7 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Type,
+		&i.TimeLimit,
 	)
 	return i, err
 }
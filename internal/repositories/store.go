@@ -10,6 +10,11 @@ type Store interface {
 	Querier
 	DeleteSlideTx(ctx context.Context, id string) error
 	DeleteQuestionTx(ctx context.Context, id string) error
+	UpdateLiveRoomTx(ctx context.Context, roomID string, fn func(data []byte) ([]byte, error)) error
+	SaveAnswerSelectionsTx(ctx context.Context, arg SaveAnswerSelectionsTxParams) error
+	ToggleUserQuestionVoteTx(ctx context.Context, questionID, voter string) (UserQuestion, bool, error)
+	ToggleChatReactionTx(ctx context.Context, msgID, reactor, emoji string) (bool, error)
+	SetSessionTeamsTx(ctx context.Context, arg SetSessionTeamsTxParams) error
 }
 type SQLStore struct {
 	*Queries
@@ -43,3 +43,193 @@ func (s *SQLStore) DeleteQuestionTx(ctx context.Context, id string) error {
 		return nil
 	})
 }
+
+// UpdateLiveRoomTx locks the live room row and replaces its data with the
+// result of fn. fn gets a JSON null when the room does not exist yet, and
+// nothing is written when it fails.
+func (s *SQLStore) UpdateLiveRoomTx(ctx context.Context, roomID string, fn func(data []byte) ([]byte, error)) error {
+	return s.ExecTx(ctx, func(q *Queries) error {
+		err := q.CreateLiveRoomIfNotExists(ctx, roomID)
+		if err != nil {
+			return fmt.Errorf("create live room: %w", err)
+		}
+
+		room, err := q.GetLiveRoomForUpdate(ctx, roomID)
+		if err != nil {
+			return fmt.Errorf("lock live room: %w", err)
+		}
+
+		data, err := fn(room.Data)
+		if err != nil {
+			return err
+		}
+
+		err = q.UpdateLiveRoom(ctx, UpdateLiveRoomParams{
+			RoomID: roomID,
+			Data:   data,
+		})
+		if err != nil {
+			return fmt.Errorf("update live room: %w", err)
+		}
+
+		return nil
+	})
+}
+
+type SaveAnswerSelectionsTxParams struct {
+	SessionID   string
+	Participant string
+	Username    string
+	SlideID     string
+	QuestionID  string
+	Selections  []CreateAnswerSelectionParams
+	// AnswerID is what the answer history keeps of the selections
+	AnswerID string
+	Points   int32
+}
+
+// SaveAnswerSelectionsTx replaces the selections of a participant for a
+// question and records the answer in the answer history, which the
+// leaderboard is computed from.
+func (s *SQLStore) SaveAnswerSelectionsTx(ctx context.Context, arg SaveAnswerSelectionsTxParams) error {
+	return s.ExecTx(ctx, func(q *Queries) error {
+		err := q.DeleteAnswerSelections(ctx, DeleteAnswerSelectionsParams{
+			SessionID:   arg.SessionID,
+			QuestionID:  arg.QuestionID,
+			Participant: arg.Participant,
+		})
+		if err != nil {
+			return fmt.Errorf("delete selections: %w", err)
+		}
+
+		for _, selection := range arg.Selections {
+			selection.SessionID = arg.SessionID
+			selection.Participant = arg.Participant
+			selection.Username = arg.Username
+			selection.SlideID = arg.SlideID
+			selection.QuestionID = arg.QuestionID
+			err = q.CreateAnswerSelection(ctx, selection)
+			if err != nil {
+				return fmt.Errorf("create selection: %w", err)
+			}
+		}
+
+		_, err = q.UpsertAnswerHistory(ctx, UpsertAnswerHistoryParams{
+			SessionID:   arg.SessionID,
+			Participant: arg.Participant,
+			Username:    arg.Username,
+			SlideID:     arg.SlideID,
+			QuestionID:  arg.QuestionID,
+			AnswerID:    arg.AnswerID,
+			Points:      arg.Points,
+		})
+		if err != nil {
+			return fmt.Errorf("upsert answer history: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ToggleUserQuestionVoteTx adds the vote of the voter to the question or takes
+// it back when it is already there, and counts the votes again. It reports
+// whether the voter now votes for the question.
+func (s *SQLStore) ToggleUserQuestionVoteTx(ctx context.Context, questionID, voter string) (UserQuestion, bool, error) {
+	var question UserQuestion
+	voted := false
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		deleted, err := q.DeleteUserQuestionVote(ctx, DeleteUserQuestionVoteParams{
+			QuestionID: questionID,
+			Voter:      voter,
+		})
+		if err != nil {
+			return fmt.Errorf("delete vote: %w", err)
+		}
+
+		if deleted == 0 {
+			_, err = q.CreateUserQuestionVote(ctx, CreateUserQuestionVoteParams{
+				QuestionID: questionID,
+				Voter:      voter,
+			})
+			if err != nil {
+				return fmt.Errorf("create vote: %w", err)
+			}
+			voted = true
+		}
+
+		question, err = q.UpdateUserQuestionVotes(ctx, questionID)
+		if err != nil {
+			return fmt.Errorf("count votes: %w", err)
+		}
+
+		return nil
+	})
+	return question, voted, err
+}
+
+// ToggleChatReactionTx adds the reaction of the reactor to the message or takes
+// it back when it is already there. It reports whether the reactor now reacts
+// with the emoji.
+func (s *SQLStore) ToggleChatReactionTx(ctx context.Context, msgID, reactor, emoji string) (bool, error) {
+	reacted := false
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		removed, err := q.DeleteChatReaction(ctx, DeleteChatReactionParams{
+			MsgID:   msgID,
+			Reactor: reactor,
+			Emoji:   emoji,
+		})
+		if err != nil {
+			return fmt.Errorf("delete reaction: %w", err)
+		}
+
+		if removed == 0 {
+			_, err = q.CreateChatReaction(ctx, CreateChatReactionParams{
+				MsgID:   msgID,
+				Reactor: reactor,
+				Emoji:   emoji,
+			})
+			if err != nil {
+				return fmt.Errorf("create reaction: %w", err)
+			}
+			reacted = true
+		}
+
+		return nil
+	})
+	return reacted, err
+}
+
+type SetSessionTeamsTxParams struct {
+	SessionID string
+	// Teams are the teams of the session, members of other teams are dropped
+	Teams   []string
+	Members []UpsertSessionTeamMemberParams
+}
+
+// SetSessionTeamsTx drops the members of the teams the session no longer has
+// and saves the teams of the members that changed.
+func (s *SQLStore) SetSessionTeamsTx(ctx context.Context, arg SetSessionTeamsTxParams) error {
+	return s.ExecTx(ctx, func(q *Queries) error {
+		teams := arg.Teams
+		if teams == nil {
+			teams = []string{}
+		}
+		err := q.DeleteSessionTeamMembersNotIn(ctx, DeleteSessionTeamMembersNotInParams{
+			SessionID: arg.SessionID,
+			Teams:     teams,
+		})
+		if err != nil {
+			return fmt.Errorf("delete team members: %w", err)
+		}
+
+		for _, member := range arg.Members {
+			member.SessionID = arg.SessionID
+			err = q.UpsertSessionTeamMember(ctx, member)
+			if err != nil {
+				return fmt.Errorf("upsert team member: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
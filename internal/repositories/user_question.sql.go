@@ -10,7 +10,7 @@ import (
 )
 
 const getUserQuestion = `-- name: GetUserQuestion :one
-SELECT question_id, slide_id, username, content, votes, answered, created_at
+SELECT question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
 FROM "user_question"
 WHERE question_id = $1
 `
@@ -26,12 +26,15 @@ func (q *Queries) GetUserQuestion(ctx context.Context, questionID string) (UserQ
 		&i.Votes,
 		&i.Answered,
 		&i.CreatedAt,
+		&i.SessionID,
+		&i.Status,
+		&i.Anonymous,
 	)
 	return i, err
 }
 
 const listUserQuestion = `-- name: ListUserQuestion :many
-SELECT question_id, slide_id, username, content, votes, answered, created_at
+SELECT question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
 FROM "user_question"
 WHERE slide_id = $1
 ORDER BY created_at DESC
@@ -54,6 +57,50 @@ func (q *Queries) ListUserQuestion(ctx context.Context, slideID string) ([]UserQ
 			&i.Votes,
 			&i.Answered,
 			&i.CreatedAt,
+			&i.SessionID,
+			&i.Status,
+			&i.Anonymous,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserQuestionBySession = `-- name: ListUserQuestionBySession :many
+SELECT question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
+FROM "user_question"
+WHERE session_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListUserQuestionBySession(ctx context.Context, sessionID string) ([]UserQuestion, error) {
+	rows, err := q.db.QueryContext(ctx, listUserQuestionBySession, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserQuestion{}
+	for rows.Next() {
+		var i UserQuestion
+		if err := rows.Scan(
+			&i.QuestionID,
+			&i.SlideID,
+			&i.Username,
+			&i.Content,
+			&i.Votes,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.SessionID,
+			&i.Status,
+			&i.Anonymous,
 		); err != nil {
 			return nil, err
 		}
@@ -72,7 +119,7 @@ const toggleUserQuestionAnswered = `-- name: ToggleUserQuestionAnswered :one
 UPDATE "user_question"
 SET answered = NOT answered
 WHERE question_id = $1
-RETURNING question_id, slide_id, username, content, votes, answered, created_at
+RETURNING question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
 `
 
 func (q *Queries) ToggleUserQuestionAnswered(ctx context.Context, questionID string) (UserQuestion, error) {
@@ -86,6 +133,96 @@ func (q *Queries) ToggleUserQuestionAnswered(ctx context.Context, questionID str
 		&i.Votes,
 		&i.Answered,
 		&i.CreatedAt,
+		&i.SessionID,
+		&i.Status,
+		&i.Anonymous,
+	)
+	return i, err
+}
+
+const updateUserQuestionContent = `-- name: UpdateUserQuestionContent :one
+UPDATE "user_question"
+SET content = $2
+WHERE question_id = $1
+RETURNING question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
+`
+
+type UpdateUserQuestionContentParams struct {
+	QuestionID string `json:"question_id"`
+	Content    string `json:"content"`
+}
+
+func (q *Queries) UpdateUserQuestionContent(ctx context.Context, arg UpdateUserQuestionContentParams) (UserQuestion, error) {
+	row := q.db.QueryRowContext(ctx, updateUserQuestionContent, arg.QuestionID, arg.Content)
+	var i UserQuestion
+	err := row.Scan(
+		&i.QuestionID,
+		&i.SlideID,
+		&i.Username,
+		&i.Content,
+		&i.Votes,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.SessionID,
+		&i.Status,
+		&i.Anonymous,
+	)
+	return i, err
+}
+
+const updateUserQuestionStatus = `-- name: UpdateUserQuestionStatus :one
+UPDATE "user_question"
+SET status = $2
+WHERE question_id = $1
+RETURNING question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
+`
+
+type UpdateUserQuestionStatusParams struct {
+	QuestionID string `json:"question_id"`
+	Status     string `json:"status"`
+}
+
+func (q *Queries) UpdateUserQuestionStatus(ctx context.Context, arg UpdateUserQuestionStatusParams) (UserQuestion, error) {
+	row := q.db.QueryRowContext(ctx, updateUserQuestionStatus, arg.QuestionID, arg.Status)
+	var i UserQuestion
+	err := row.Scan(
+		&i.QuestionID,
+		&i.SlideID,
+		&i.Username,
+		&i.Content,
+		&i.Votes,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.SessionID,
+		&i.Status,
+		&i.Anonymous,
+	)
+	return i, err
+}
+
+const updateUserQuestionVotes = `-- name: UpdateUserQuestionVotes :one
+UPDATE "user_question"
+SET votes = (
+    SELECT count(*) FROM "user_question_vote" v WHERE v.question_id = $1
+)
+WHERE question_id = $1
+RETURNING question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
+`
+
+func (q *Queries) UpdateUserQuestionVotes(ctx context.Context, questionID string) (UserQuestion, error) {
+	row := q.db.QueryRowContext(ctx, updateUserQuestionVotes, questionID)
+	var i UserQuestion
+	err := row.Scan(
+		&i.QuestionID,
+		&i.SlideID,
+		&i.Username,
+		&i.Content,
+		&i.Votes,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.SessionID,
+		&i.Status,
+		&i.Anonymous,
 	)
 	return i, err
 }
@@ -96,14 +233,20 @@ INSERT INTO "user_question" (
   "slide_id",
   "username",
   "content",
+  "session_id",
+  "status",
+  "anonymous",
   "created_at"
 ) VALUES (
-    $1, $2, $3, $4, now()
+    $1, $2, $3, $4, $5, $6, $7, now()
 ) ON CONFLICT (question_id) DO UPDATE SET
     "slide_id" = $2,
     "username" = $3,
-    "content" = $4
-RETURNING question_id, slide_id, username, content, votes, answered, created_at
+    "content" = $4,
+    "session_id" = $5,
+    "status" = $6,
+    "anonymous" = $7
+RETURNING question_id, slide_id, username, content, votes, answered, created_at, session_id, status, anonymous
 `
 
 type UpsertUserQuestionParams struct {
@@ -111,6 +254,9 @@ type UpsertUserQuestionParams struct {
 	SlideID    string `json:"slide_id"`
 	Username   string `json:"username"`
 	Content    string `json:"content"`
+	SessionID  string `json:"session_id"`
+	Status     string `json:"status"`
+	Anonymous  bool   `json:"anonymous"`
 }
 
 func (q *Queries) UpsertUserQuestion(ctx context.Context, arg UpsertUserQuestionParams) (UserQuestion, error) {
@@ -119,6 +265,9 @@ func (q *Queries) UpsertUserQuestion(ctx context.Context, arg UpsertUserQuestion
 		arg.SlideID,
 		arg.Username,
 		arg.Content,
+		arg.SessionID,
+		arg.Status,
+		arg.Anonymous,
 	)
 	var i UserQuestion
 	err := row.Scan(
@@ -129,28 +278,9 @@ func (q *Queries) UpsertUserQuestion(ctx context.Context, arg UpsertUserQuestion
 		&i.Votes,
 		&i.Answered,
 		&i.CreatedAt,
-	)
-	return i, err
-}
-
-const upvoteUserQuestion = `-- name: UpvoteUserQuestion :one
-UPDATE "user_question"
-SET votes = votes + 1
-WHERE question_id = $1
-RETURNING question_id, slide_id, username, content, votes, answered, created_at
-`
-
-func (q *Queries) UpvoteUserQuestion(ctx context.Context, questionID string) (UserQuestion, error) {
-	row := q.db.QueryRowContext(ctx, upvoteUserQuestion, questionID)
-	var i UserQuestion
-	err := row.Scan(
-		&i.QuestionID,
-		&i.SlideID,
-		&i.Username,
-		&i.Content,
-		&i.Votes,
-		&i.Answered,
-		&i.CreatedAt,
+		&i.SessionID,
+		&i.Status,
+		&i.Anonymous,
 	)
 	return i, err
 }
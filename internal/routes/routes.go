@@ -72,6 +72,13 @@ func InitRoutes(server *services.Server, socket *socketio.Server, c *utils.Confi
 	collab.GET("/user/:user_id", server.SlideService.GetCollaboratorByUserID)
 	collab.POST("/remove", server.SlideService.RemoveCollaborator)
 
+	presentation := route.Group("/presentation")
+	presentation.GET("/pin/:pin", server.PresentationService.ResolvePin)
+	presentation.GET("/feed/:room", a.AuthOptional, server.PresentationService.StreamFeed)
+	presentation.Use(a.AuthRequired)
+	presentation.GET("/session/slide/:slide_id", server.PresentationService.ListSessionBySlideID)
+	presentation.GET("/session/:session_id", server.PresentationService.GetSessionResult)
+
 	question := route.Group("/question")
 	question.GET("/:question_id", server.QuestionService.GetQuestionByID)
 	question.GET("/slide/:slide_id", server.QuestionService.GetQuestionBySlideID)
@@ -81,6 +88,7 @@ func InitRoutes(server *services.Server, socket *socketio.Server, c *utils.Confi
 	question.DELETE("/:question_id", server.QuestionService.DeleteQuestion)
 
 	answer := route.Group("/answer")
+	answer.Use(a.AuthOptional)
 	answer.GET("/:answer_id", server.AnswerService.GetAnswerByID)
 	answer.GET("/question/:question_id", server.AnswerService.GetAnswerByQuestionID)
 	answer.Use(a.AuthRequired)
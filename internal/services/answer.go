@@ -2,9 +2,12 @@ package services
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
 	"github.com/vtv-us/kahoot-backend/internal/repositories"
 	"github.com/vtv-us/kahoot-backend/internal/utils"
 )
@@ -25,6 +28,7 @@ type createAnswerRequest struct {
 	QuestionID string `json:"question_id" binding:"required"`
 	Index      int16  `json:"index" binding:"required"`
 	RawAnswer  string `json:"raw_answer" binding:"required"`
+	IsCorrect  bool   `json:"is_correct"`
 }
 
 func (s *AnswerService) CreateAnswer(ctx *gin.Context) {
@@ -44,6 +48,7 @@ func (s *AnswerService) CreateAnswer(ctx *gin.Context) {
 		QuestionID: req.QuestionID,
 		Index:      req.Index,
 		RawAnswer:  req.RawAnswer,
+		IsCorrect:  req.IsCorrect,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
@@ -53,6 +58,37 @@ func (s *AnswerService) CreateAnswer(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, question)
 }
 
+// publicAnswer is an answer without whether it is correct, for anyone but the
+// owner and collaborators of its slide.
+type publicAnswer struct {
+	ID         string    `json:"id"`
+	QuestionID string    `json:"question_id"`
+	Index      int16     `json:"index"`
+	RawAnswer  string    `json:"raw_answer"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newPublicAnswer(answer entities.Answer) publicAnswer {
+	return publicAnswer{
+		ID:         answer.ID,
+		QuestionID: answer.QuestionID,
+		Index:      answer.Index,
+		RawAnswer:  answer.RawAnswer,
+		CreatedAt:  answer.CreatedAt,
+		UpdatedAt:  answer.UpdatedAt,
+	}
+}
+
+// canSeeCorrect reports whether the user of the request can edit the question,
+// and so see which of its answers are correct.
+func canSeeCorrect(ctx *gin.Context, db repositories.Store, questionID string) bool {
+	if ctx.GetString(constants.Token_USER_ID) == "" {
+		return false
+	}
+	return checkQuestionPermission(ctx, db, questionID) == nil
+}
+
 type getAnswerByQuestionIDRequest struct {
 	QuestionID string `uri:"question_id" binding:"required"`
 }
@@ -70,7 +106,15 @@ func (s *AnswerService) GetAnswerByQuestionID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, answer)
+	if canSeeCorrect(ctx, s.DB, req.QuestionID) {
+		ctx.JSON(http.StatusOK, answer)
+		return
+	}
+	res := make([]publicAnswer, 0, len(answer))
+	for _, a := range answer {
+		res = append(res, newPublicAnswer(a.Answer))
+	}
+	ctx.JSON(http.StatusOK, res)
 }
 
 type getAnswerByIDRequest struct {
@@ -90,13 +134,18 @@ func (s *AnswerService) GetAnswerByID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, question)
+	if canSeeCorrect(ctx, s.DB, question.QuestionID) {
+		ctx.JSON(http.StatusOK, question)
+		return
+	}
+	ctx.JSON(http.StatusOK, newPublicAnswer(question.Answer))
 }
 
 type updateAnswerRequest struct {
 	AnswerID  string `json:"answer_id" binding:"required"`
 	Index     int16  `json:"index" binding:"required"`
 	RawAnswer string `json:"raw_answer" binding:"required"`
+	IsCorrect bool   `json:"is_correct"`
 }
 
 func (s *AnswerService) UpdateAnswer(ctx *gin.Context) {
@@ -115,6 +164,7 @@ func (s *AnswerService) UpdateAnswer(ctx *gin.Context) {
 		ID:        req.AnswerID,
 		Index:     req.Index,
 		RawAnswer: req.RawAnswer,
+		IsCorrect: req.IsCorrect,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+)
+
+func TestPublicAnswerHidesCorrect(t *testing.T) {
+	data, err := json.Marshal(newPublicAnswer(entities.Answer{ID: "a", QuestionID: "q", IsCorrect: true}))
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.Equal(t, "a", fields["id"])
+	require.NotContains(t, fields, "is_correct")
+}
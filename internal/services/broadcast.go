@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+// Broadcaster sends an event to every client in a room. The socket server only
+// reaches its own clients, the Postgres broadcaster reaches every instance.
+type Broadcaster interface {
+	BroadcastToRoom(namespace, room, event string, args ...interface{}) bool
+}
+
+// kickCloseDelay leaves the kicked event time to reach the client before its
+// connection is closed.
+const kickCloseDelay = 500 * time.Millisecond
+
+// LocalBroadcaster emits to the clients of this instance and closes the
+// connections a kicked event is sent to.
+type LocalBroadcaster struct {
+	*socketio.Server
+}
+
+var _ Broadcaster = LocalBroadcaster{}
+
+func (b LocalBroadcaster) BroadcastToRoom(namespace, room, event string, args ...interface{}) bool {
+	ok := b.Server.BroadcastToRoom(namespace, room, event, args...)
+	if event == kickedEvent {
+		time.AfterFunc(kickCloseDelay, func() {
+			// closing leaves the rooms, which cannot be done while iterating them
+			var conns []socketio.Conn
+			b.Server.ForEach(namespace, room, func(c socketio.Conn) {
+				conns = append(conns, c)
+			})
+			for _, c := range conns {
+				c.Close()
+			}
+		})
+	}
+	return ok
+}
+
+// NewBroadcaster returns the broadcaster of the config, the events that reach
+// the clients of this instance also reach the feeds of their room.
+func NewBroadcaster(db repositories.Store, c *utils.Config, socket *socketio.Server, feed *LiveFeed) (Broadcaster, error) {
+	local := feed.wrap(LocalBroadcaster{socket})
+	switch c.Broadcaster {
+	case "", constants.Broadcaster_LOCAL:
+		return local, nil
+	case constants.Broadcaster_POSTGRES:
+		return NewPostgresBroadcaster(db, c.DBUrl, local)
+	default:
+		return nil, fmt.Errorf("unknown broadcaster: %s", c.Broadcaster)
+	}
+}
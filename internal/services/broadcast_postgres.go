@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+const (
+	liveEventChannel = "live_event"
+	// NOTIFY payloads are limited to 8000 bytes, bigger events are stored in
+	// the live_event table and only their ID is sent
+	maxNotifyPayload = 7000
+	// stored events are only needed until every instance has read them
+	liveEventTTL = time.Minute
+
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+// liveEvent is a room broadcast as it is sent to the other instances.
+type liveEvent struct {
+	Instance  string
+	Namespace string
+	Room      string
+	Event     string
+	Args      []json.RawMessage
+}
+
+// PostgresBroadcaster emits room events to the local clients and publishes
+// them with NOTIFY, every other instance listens and re-emits them to its own
+// clients.
+type PostgresBroadcaster struct {
+	DB repositories.Store
+
+	local    Broadcaster
+	instance string
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+var _ Broadcaster = (*PostgresBroadcaster)(nil)
+
+func NewPostgresBroadcaster(db repositories.Store, dbURL string, local Broadcaster) (*PostgresBroadcaster, error) {
+	listener := pq.NewListener(dbURL, listenerMinReconnect, listenerMaxReconnect, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			fmt.Println("live event listener:", err)
+		}
+	})
+	if err := listener.Listen(liveEventChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("listen live events: %w", err)
+	}
+
+	b := &PostgresBroadcaster{
+		DB:       db,
+		local:    local,
+		instance: uuid.New().String(),
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+	go b.listen()
+	return b, nil
+}
+
+func (b *PostgresBroadcaster) BroadcastToRoom(namespace, room, event string, args ...interface{}) bool {
+	ok := b.local.BroadcastToRoom(namespace, room, event, args...)
+	if err := b.publish(context.Background(), namespace, room, event, args); err != nil {
+		fmt.Println("publish live event:", err)
+	}
+	return ok
+}
+
+func (b *PostgresBroadcaster) publish(ctx context.Context, namespace, room, event string, args []interface{}) error {
+	ev := liveEvent{
+		Instance:  b.instance,
+		Namespace: namespace,
+		Room:      room,
+		Event:     event,
+		Args:      make([]json.RawMessage, 0, len(args)),
+	}
+	for _, arg := range args {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			return err
+		}
+		ev.Args = append(ev.Args, data)
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if len(payload) <= maxNotifyPayload {
+		return b.DB.NotifyLiveEvent(ctx, string(payload))
+	}
+
+	if err := b.DB.DeleteLiveEventsBefore(ctx, time.Now().Add(-liveEventTTL)); err != nil {
+		return err
+	}
+	id, err := b.DB.CreateLiveEvent(ctx, payload)
+	if err != nil {
+		return err
+	}
+	return b.DB.NotifyLiveEvent(ctx, strconv.FormatInt(id, 10))
+}
+
+func (b *PostgresBroadcaster) listen() {
+	for {
+		select {
+		case <-b.done:
+			return
+		case n, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+			// nil after the connection has been re-established
+			if n == nil {
+				continue
+			}
+			if err := b.receive(context.Background(), n.Extra); err != nil {
+				fmt.Println("receive live event:", err)
+			}
+		case <-time.After(listenerPingInterval):
+			go b.listener.Ping()
+		}
+	}
+}
+
+func (b *PostgresBroadcaster) receive(ctx context.Context, payload string) error {
+	data := []byte(payload)
+	if id, err := strconv.ParseInt(payload, 10, 64); err == nil {
+		stored, err := b.DB.GetLiveEvent(ctx, id)
+		if err != nil {
+			return err
+		}
+		data = stored.Payload
+	}
+
+	var ev liveEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return err
+	}
+	// the publishing instance has already emitted it to its own clients
+	if ev.Instance == b.instance {
+		return nil
+	}
+
+	args := make([]interface{}, len(ev.Args))
+	for i, arg := range ev.Args {
+		args[i] = arg
+	}
+	b.local.BroadcastToRoom(ev.Namespace, ev.Room, ev.Event, args...)
+	return nil
+}
+
+// Close stops listening for events of the other instances.
+func (b *PostgresBroadcaster) Close() error {
+	close(b.done)
+	return b.listener.Close()
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/googollee/go-socket.io/engineio"
+	"github.com/googollee/go-socket.io/engineio/session"
+	"github.com/googollee/go-socket.io/engineio/transport"
+	"github.com/googollee/go-socket.io/engineio/transport/polling"
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+const testEventTimeout = 5 * time.Second
+
+// newTestSocketServer runs a socket server whose clients join a room with the
+// "join" event.
+func newTestSocketServer(t *testing.T) (*socketio.Server, string) {
+	socket := socketio.NewServer(nil)
+	var mu sync.Mutex
+	var conns []socketio.Conn
+	socket.OnConnect("/", func(s socketio.Conn) error {
+		mu.Lock()
+		defer mu.Unlock()
+		conns = append(conns, s)
+		return nil
+	})
+	socket.OnEvent("/", "join", func(s socketio.Conn, room string) string {
+		s.Join(room)
+		return room
+	})
+	go socket.Serve()
+	httpServer := httptest.NewServer(socket)
+	t.Cleanup(func() {
+		// polling requests are held open until their connection is closed
+		mu.Lock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+		socket.Close()
+		httpServer.Close()
+	})
+	return socket, httpServer.URL
+}
+
+type testSocketClient struct {
+	conn   engineio.Conn
+	frames chan string
+}
+
+func newTestSocketClient(t *testing.T, url, room string) *testSocketClient {
+	dialer := engineio.Dialer{
+		Transports: []transport.Transport{polling.Default},
+	}
+	conn, err := dialer.Dial(url+"/socket.io/", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	c := &testSocketClient{
+		conn:   conn,
+		frames: make(chan string, 16),
+	}
+	go func() {
+		defer close(c.frames)
+		for {
+			_, r, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+			b, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return
+			}
+			c.frames <- strings.TrimSpace(string(b))
+		}
+	}()
+
+	require.Equal(t, "0", c.next(t))
+	w, err := conn.NextWriter(session.TEXT)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(`20["join","` + room + `"]`))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	// the ack tells the client it is in the room
+	require.Equal(t, `30["`+room+`"]`, c.next(t))
+	return c
+}
+
+func (c *testSocketClient) next(t *testing.T) string {
+	select {
+	case frame, ok := <-c.frames:
+		require.True(t, ok, "connection closed")
+		return frame
+	case <-time.After(testEventTimeout):
+		t.Fatal("no event received")
+		return ""
+	}
+}
+
+func (c *testSocketClient) requireClosed(t *testing.T) {
+	for {
+		select {
+		case frame, ok := <-c.frames:
+			if !ok {
+				return
+			}
+			t.Fatalf("unexpected event: %s", frame)
+		case <-time.After(testEventTimeout):
+			t.Fatal("connection not closed")
+		}
+	}
+}
+
+func (c *testSocketClient) requireNoEvent(t *testing.T) {
+	select {
+	case frame := <-c.frames:
+		t.Fatalf("unexpected event: %s", frame)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func testEventFrame(t *testing.T, args ...interface{}) string {
+	b, err := json.Marshal(args)
+	require.NoError(t, err)
+	return "2" + string(b)
+}
+
+// fakeEventStore delivers NOTIFY payloads to every broadcaster in the process.
+type fakeEventStore struct {
+	repositories.Store
+
+	mu          sync.Mutex
+	events      map[int64]json.RawMessage
+	subscribers []*PostgresBroadcaster
+}
+
+func (f *fakeEventStore) NotifyLiveEvent(ctx context.Context, payload string) error {
+	f.mu.Lock()
+	subscribers := append([]*PostgresBroadcaster(nil), f.subscribers...)
+	f.mu.Unlock()
+
+	for _, b := range subscribers {
+		if err := b.receive(ctx, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeEventStore) CreateLiveEvent(ctx context.Context, payload json.RawMessage) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := int64(len(f.events) + 1)
+	f.events[id] = payload
+	return id, nil
+}
+
+func (f *fakeEventStore) GetLiveEvent(ctx context.Context, id int64) (repositories.LiveEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ev repositories.LiveEvent
+	ev.ID = id
+	ev.Payload = f.events[id]
+	return ev, nil
+}
+
+func (f *fakeEventStore) DeleteLiveEventsBefore(ctx context.Context, createdAt time.Time) error {
+	return nil
+}
+
+func testBroadcastFanOut(t *testing.T, first, second Broadcaster, firstURL, secondURL string) {
+	room := utils.RandomString(12)
+	firstClient := newTestSocketClient(t, firstURL, room)
+	secondClient := newTestSocketClient(t, secondURL, room)
+	otherRoomClient := newTestSocketClient(t, secondURL, utils.RandomString(12))
+
+	first.BroadcastToRoom("/", room, "chat", "alice", "hello")
+	want := testEventFrame(t, "chat", "alice", "hello")
+	require.Equal(t, want, firstClient.next(t))
+	require.Equal(t, want, secondClient.next(t))
+
+	second.BroadcastToRoom("/", room, "showStatistic", map[string]int{"answer": 3})
+	want = testEventFrame(t, "showStatistic", map[string]int{"answer": 3})
+	require.Equal(t, want, firstClient.next(t))
+	require.Equal(t, want, secondClient.next(t))
+
+	// bigger than a NOTIFY payload
+	long := strings.Repeat("a", 2*maxNotifyPayload)
+	first.BroadcastToRoom("/", room, "chat", "bob", long)
+	want = testEventFrame(t, "chat", "bob", long)
+	require.Equal(t, want, firstClient.next(t))
+	require.Equal(t, want, secondClient.next(t))
+
+	firstClient.requireNoEvent(t)
+	secondClient.requireNoEvent(t)
+	otherRoomClient.requireNoEvent(t)
+}
+
+func TestPostgresBroadcasterRelaysEvents(t *testing.T) {
+	store := &fakeEventStore{events: make(map[int64]json.RawMessage)}
+	firstSocket, firstURL := newTestSocketServer(t)
+	secondSocket, secondURL := newTestSocketServer(t)
+
+	first := &PostgresBroadcaster{DB: store, local: LocalBroadcaster{firstSocket}, instance: utils.RandomString(12)}
+	second := &PostgresBroadcaster{DB: store, local: LocalBroadcaster{secondSocket}, instance: utils.RandomString(12)}
+	store.subscribers = []*PostgresBroadcaster{first, second}
+
+	testBroadcastFanOut(t, first, second, firstURL, secondURL)
+}
+
+func TestKickClosesConnectionOnOtherInstance(t *testing.T) {
+	store := &fakeEventStore{events: make(map[int64]json.RawMessage)}
+	firstSocket, _ := newTestSocketServer(t)
+	secondSocket, secondURL := newTestSocketServer(t)
+
+	first := &PostgresBroadcaster{DB: store, local: LocalBroadcaster{firstSocket}, instance: utils.RandomString(12)}
+	second := &PostgresBroadcaster{DB: store, local: LocalBroadcaster{secondSocket}, instance: utils.RandomString(12)}
+	store.subscribers = []*PostgresBroadcaster{first, second}
+
+	room := connRoom(utils.RandomString(12))
+	kicked := newTestSocketClient(t, secondURL, room)
+	other := newTestSocketClient(t, secondURL, utils.RandomString(12))
+
+	notice := KickNotice{RoomID: utils.RandomString(12), Banned: true}
+	first.BroadcastToRoom("/", room, kickedEvent, notice)
+	require.Equal(t, testEventFrame(t, kickedEvent, notice), kicked.next(t))
+	kicked.requireClosed(t)
+	other.requireNoEvent(t)
+}
+
+func TestPostgresBroadcaster(t *testing.T) {
+	db, config := newTestStore(t)
+	firstSocket, firstURL := newTestSocketServer(t)
+	secondSocket, secondURL := newTestSocketServer(t)
+
+	first, err := NewPostgresBroadcaster(db, config.DBUrl, firstSocket)
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := NewPostgresBroadcaster(db, config.DBUrl, secondSocket)
+	require.NoError(t, err)
+	defer second.Close()
+
+	testBroadcastFanOut(t, first, second, firstURL, secondURL)
+}
@@ -2,31 +2,296 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/vtv-us/kahoot-backend/internal/entities"
 	"github.com/vtv-us/kahoot-backend/internal/repositories"
 )
 
-func (s *SlideService) SaveChatMsg(slideID, username, content string) error {
-	_, err := s.DB.SaveChat(context.Background(), repositories.SaveChatParams{
-		ID:       uuid.NewString(),
-		SlideID:  slideID,
-		Username: username,
-		Content:  content,
+const (
+	defaultChatPageSize = 50
+	maxChatPageSize     = 100
+	// maxEmojiLength is in runes, enough for emoji joined with modifiers
+	maxEmojiLength = 8
+)
+
+// chatCursorEnd is the cursor of the first page, later than any message.
+var chatCursorEnd = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+type ChatReaction struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+	// Reacted is whether the participant asking reacted with the emoji
+	Reacted bool `json:"reacted"`
+}
+
+// ChatMessage is a new message sent to the room, ParentID is set on replies.
+type ChatMessage struct {
+	Username string `json:"username"`
+	Msg      string `json:"msg"`
+	MsgID    string `json:"msg_id"`
+	ParentID string `json:"parent_id"`
+}
+
+// ChatEntry is a chat message with its reactions and how many replies it
+// has, replies cannot be replied to so they have none.
+type ChatEntry struct {
+	entities.ChatMsg
+	Reactions  []ChatReaction `json:"reactions"`
+	ReplyCount int            `json:"reply_count"`
+}
+
+// ChatPage is a page of chat history from the newest message, NextCursor is
+// empty on the last page.
+type ChatPage struct {
+	Messages   []ChatEntry `json:"messages"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+type ChatReplies struct {
+	ParentID string      `json:"parent_id"`
+	Replies  []ChatEntry `json:"replies"`
+}
+
+// ChatReactions is the reactions of a message after one of them changed.
+type ChatReactions struct {
+	MsgID     string         `json:"msg_id"`
+	Reactions []ChatReaction `json:"reactions"`
+}
+
+// ChatReacted tells the participant whether its reaction was added or taken
+// back.
+type ChatReacted struct {
+	MsgID   string `json:"msg_id"`
+	Emoji   string `json:"emoji"`
+	Reacted bool   `json:"reacted"`
+}
+
+// SaveChatMsg saves a message, or a reply when parentID is set. Replies to a
+// reply go to the message it replied to so threads are one level deep.
+func (s *SlideService) SaveChatMsg(sessionID, slideID, username, content, parentID string) (entities.ChatMsg, error) {
+	ctx := context.Background()
+	if parentID != "" {
+		parent, err := s.DB.GetChatMsg(ctx, repositories.GetChatMsgParams{
+			ID:        parentID,
+			SessionID: sessionID,
+		})
+		if err == sql.ErrNoRows {
+			return entities.ChatMsg{}, fmt.Errorf("chat message does not exist")
+		}
+		if err != nil {
+			return entities.ChatMsg{}, err
+		}
+		if parent.Deleted {
+			return entities.ChatMsg{}, fmt.Errorf("you cannot reply to a deleted message")
+		}
+		if parent.ParentID != "" {
+			parentID = parent.ParentID
+		}
+	}
+
+	msg, err := s.DB.SaveChat(ctx, repositories.SaveChatParams{
+		ID:        uuid.NewString(),
+		SlideID:   slideID,
+		Username:  username,
+		Content:   content,
+		SessionID: sessionID,
+		ParentID:  parentID,
 	})
+	if err != nil {
+		return entities.ChatMsg{}, err
+	}
+	return msg.ChatMsg, nil
+}
+
+// DeleteChatMsg hides a message of the session from the chat.
+func (s *SlideService) DeleteChatMsg(sessionID, msgID string) error {
+	_, err := s.DB.DeleteChatMsg(context.Background(), repositories.DeleteChatMsgParams{
+		ID:        msgID,
+		SessionID: sessionID,
+	})
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("chat message does not exist")
+	}
 	return err
 }
 
-func (s *SlideService) GetChatMsgs(slideID string) ([]entities.ChatMsg, error) {
-	chatMsg, err := s.DB.GetChatBySlide(context.Background(), slideID)
+// GetChatPage returns the messages before the cursor, newest first, with the
+// reactions of the reactor marked. Replies are fetched with GetChatReplies.
+func (s *SlideService) GetChatPage(sessionID, reactor, cursor string, limit int) (ChatPage, error) {
+	ctx := context.Background()
+	if limit <= 0 {
+		limit = defaultChatPageSize
+	}
+	if limit > maxChatPageSize {
+		limit = maxChatPageSize
+	}
+	before, beforeID, err := decodeChatCursor(cursor)
+	if err != nil {
+		return ChatPage{}, err
+	}
+
+	msgs, err := s.DB.ListChatPage(ctx, repositories.ListChatPageParams{
+		SessionID:  sessionID,
+		BeforeTime: before,
+		BeforeID:   beforeID,
+		Limit:      int32(limit),
+	})
+	if err != nil {
+		return ChatPage{}, err
+	}
+	page := ChatPage{}
+	if page.Messages, err = s.chatMessages(ctx, reactor, msgs, true); err != nil {
+		return ChatPage{}, err
+	}
+	if len(msgs) == limit {
+		page.NextCursor = encodeChatCursor(msgs[len(msgs)-1].ChatMsg)
+	}
+	return page, nil
+}
+
+// GetChatReplies returns the replies to a message, oldest first.
+func (s *SlideService) GetChatReplies(sessionID, reactor, parentID string) ([]ChatEntry, error) {
+	ctx := context.Background()
+	msgs, err := s.DB.ListChatReplies(ctx, repositories.ListChatRepliesParams{
+		SessionID: sessionID,
+		ParentID:  parentID,
+	})
 	if err != nil {
 		return nil, err
 	}
+	return s.chatMessages(ctx, reactor, msgs, false)
+}
 
-	chatMsgEnt := make([]entities.ChatMsg, 0, len(chatMsg))
-	for _, msg := range chatMsg {
-		chatMsgEnt = append(chatMsgEnt, msg.ChatMsg)
+func (s *SlideService) chatMessages(ctx context.Context, reactor string, msgs []repositories.ChatMsg, withReplies bool) ([]ChatEntry, error) {
+	res := make([]ChatEntry, 0, len(msgs))
+	ids := make([]string, 0, len(msgs))
+	index := make(map[string]int, len(msgs))
+	for i, msg := range msgs {
+		res = append(res, ChatEntry{
+			ChatMsg:   publicChatMsg(msg.ChatMsg),
+			Reactions: []ChatReaction{},
+		})
+		ids = append(ids, msg.ID)
+		index[msg.ID] = i
+	}
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	reactions, err := s.DB.CountChatReactions(ctx, repositories.CountChatReactionsParams{
+		Reactor: reactor,
+		MsgIds:  ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range reactions {
+		msg := &res[index[row.MsgID]]
+		msg.Reactions = append(msg.Reactions, ChatReaction{
+			Emoji:   row.Emoji,
+			Count:   int(row.Count),
+			Reacted: row.Reacted,
+		})
+	}
+
+	if !withReplies {
+		return res, nil
+	}
+	replies, err := s.DB.CountChatReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range replies {
+		res[index[row.ParentID]].ReplyCount = int(row.Count)
+	}
+	return res, nil
+}
+
+// ToggleChatReaction adds the reaction of a participant to a message or takes
+// it back when it was there, and returns whether it was added with the counts
+// of the message. The reactor is the key of the participant.
+func (s *SlideService) ToggleChatReaction(sessionID, msgID, reactor, emoji string) (ChatReactions, bool, error) {
+	ctx := context.Background()
+	if err := validateEmoji(emoji); err != nil {
+		return ChatReactions{}, false, err
+	}
+	msg, err := s.DB.GetChatMsg(ctx, repositories.GetChatMsgParams{
+		ID:        msgID,
+		SessionID: sessionID,
+	})
+	if err == sql.ErrNoRows {
+		return ChatReactions{}, false, fmt.Errorf("chat message does not exist")
+	}
+	if err != nil {
+		return ChatReactions{}, false, err
+	}
+	if msg.Deleted {
+		return ChatReactions{}, false, fmt.Errorf("you cannot react to a deleted message")
+	}
+
+	reacted, err := s.DB.ToggleChatReactionTx(ctx, msgID, reactor, emoji)
+	if err != nil {
+		return ChatReactions{}, false, err
+	}
+
+	// the counts are broadcast so no one is marked as having reacted
+	counts, err := s.chatMessages(ctx, "", []repositories.ChatMsg{msg}, false)
+	if err != nil {
+		return ChatReactions{}, false, err
+	}
+	return ChatReactions{MsgID: msgID, Reactions: counts[0].Reactions}, reacted, nil
+}
+
+// validateEmoji accepts a single emoji, possibly joined with modifiers, and
+// nothing that reads as text.
+func validateEmoji(emoji string) error {
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxEmojiLength {
+		return fmt.Errorf("please react with an emoji")
+	}
+	symbol := false
+	for _, c := range emoji {
+		switch {
+		case unicode.IsSymbol(c):
+			symbol = true
+		case c == '\u200d', unicode.Is(unicode.Variation_Selector, c):
+		default:
+			return fmt.Errorf("please react with an emoji")
+		}
+	}
+	if !symbol {
+		return fmt.Errorf("please react with an emoji")
+	}
+	return nil
+}
+
+// encodeChatCursor returns the cursor of the page after the message.
+func encodeChatCursor(msg entities.ChatMsg) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(msg.CreatedAt.UTC().Format(time.RFC3339Nano) + " " + msg.ID))
+}
+
+func decodeChatCursor(cursor string) (time.Time, string, error) {
+	if cursor == "" {
+		return chatCursorEnd, "", nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid chat cursor")
+	}
+	createdAt, id, ok := strings.Cut(string(b), " ")
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("invalid chat cursor")
+	}
+	before, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid chat cursor")
 	}
-	return chatMsgEnt, nil
+	return before, id, nil
 }
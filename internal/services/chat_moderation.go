@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+)
+
+const (
+	maxChatLength = 500
+	// maxSlowMode is the longest wait between chat messages, in seconds
+	maxSlowMode = 300
+)
+
+var (
+	errChatMuted   = errors.New("you are muted in this room")
+	errChatBlocked = errors.New("your message contains words that are not allowed")
+
+	chatWord = regexp.MustCompile(`\S+`)
+)
+
+// ChatFilter masks or rejects the messages with words of the blocklist.
+type ChatFilter struct {
+	words  []string
+	reject bool
+}
+
+// newChatFilter builds the filter from the comma separated blocklist of the
+// config, the words are matched like the blocked words of nicknames: as whole
+// words, or as the start or end of words when they start or end with *.
+func newChatFilter(blocklist, mode string) *ChatFilter {
+	f := &ChatFilter{reject: mode == constants.ChatFilter_REJECT}
+	for _, word := range strings.Split(blocklist, ",") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			f.words = append(f.words, word)
+		}
+	}
+	return f
+}
+
+// Filter checks a chat message and returns it with the blocked words masked.
+func (f *ChatFilter) Filter(msg string) (string, error) {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return "", fmt.Errorf("please enter a message")
+	}
+	if utf8.RuneCountInString(msg) > maxChatLength {
+		return "", fmt.Errorf("message must be at most %d characters", maxChatLength)
+	}
+	if len(f.words) == 0 {
+		return msg, nil
+	}
+
+	blocked := false
+	msg = chatWord.ReplaceAllStringFunc(msg, func(word string) string {
+		if !containsBlockedWord(word, f.words) {
+			return word
+		}
+		blocked = true
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+	if blocked && f.reject {
+		return "", errChatBlocked
+	}
+	return msg, nil
+}
+
+// ParticipantMuted tells hosts, and the participant itself, that it was muted
+// or unmuted.
+type ParticipantMuted struct {
+	Participant ParticipantInfo `json:"participant"`
+	Muted       bool            `json:"muted"`
+}
+
+// setMuted mutes or unmutes a participant in chat, hosts cannot be muted.
+func (r *LiveRoom) setMuted(key string, muted bool) (Participant, error) {
+	p := r.participant(key)
+	if p == nil {
+		return Participant{}, fmt.Errorf("participant does not exist")
+	}
+	if p.IsTeacher {
+		return Participant{}, fmt.Errorf("hosts cannot be muted")
+	}
+	if r.Muted == nil {
+		r.Muted = make(map[string]bool)
+	}
+	if muted {
+		r.Muted[p.key()] = true
+	} else {
+		delete(r.Muted, p.key())
+	}
+	return *p, nil
+}
+
+func (r *LiveRoom) setSlowMode(seconds int) error {
+	if seconds < 0 || seconds > maxSlowMode {
+		return fmt.Errorf("slow mode must be from 0 to %d seconds", maxSlowMode)
+	}
+	r.SlowMode = seconds
+	r.LastChat = nil
+	return nil
+}
+
+// allowChat checks that a participant may send a chat message now. Hosts are
+// always allowed.
+func (r *LiveRoom) allowChat(key string, now time.Time) error {
+	p := r.participant(key)
+	if p == nil {
+		return errNotInRoom
+	}
+	if p.IsTeacher {
+		return nil
+	}
+	if r.Muted[key] {
+		return errChatMuted
+	}
+	if r.SlowMode == 0 {
+		return nil
+	}
+
+	cooldown := time.Duration(r.SlowMode) * time.Second
+	if wait := r.LastChat[key].Add(cooldown).Sub(now); wait > 0 {
+		return fmt.Errorf("slow mode is on, you can send a message in %d seconds", int(math.Ceil(wait.Seconds())))
+	}
+	return nil
+}
+
+// recordChat starts a participant's slow mode wait for its next message, once
+// the one it sent at now is saved.
+func (r *LiveRoom) recordChat(key string, now time.Time) {
+	if r.SlowMode == 0 {
+		return
+	}
+	if p := r.participant(key); p == nil || p.IsTeacher {
+		return
+	}
+	if r.LastChat == nil {
+		r.LastChat = make(map[string]time.Time)
+	}
+	r.LastChat[key] = now
+}
+
+// publicChatMsg hides what a deleted message said.
+func publicChatMsg(msg entities.ChatMsg) entities.ChatMsg {
+	if msg.Deleted {
+		msg.Content = ""
+	}
+	return msg
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+)
+
+func TestChatFilter(t *testing.T) {
+	f := newChatFilter(" darn, Heck ,", constants.ChatFilter_MASK)
+	msg, err := f.Filter("  what the h3ck, this is  D.A.R.N hard ")
+	require.NoError(t, err)
+	require.Equal(t, "what the ***** this is  ******* hard", msg)
+
+	_, err = f.Filter("   ")
+	require.Error(t, err)
+
+	// words are matched whole unless they are marked with *
+	f = newChatFilter("ass,darn*", constants.ChatFilter_MASK)
+	msg, err = f.Filter("pass the class assignment, ass, darnit")
+	require.NoError(t, err)
+	require.Equal(t, "pass the class assignment, **** ******", msg)
+
+	f = newChatFilter("darn", constants.ChatFilter_REJECT)
+	_, err = f.Filter("darn it")
+	require.ErrorIs(t, err, errChatBlocked)
+	msg, err = f.Filter("fine")
+	require.NoError(t, err)
+	require.Equal(t, "fine", msg)
+
+	msg, err = newChatFilter("", constants.ChatFilter_REJECT).Filter("darn it")
+	require.NoError(t, err)
+	require.Equal(t, "darn it", msg)
+}
+
+func TestMuteParticipant(t *testing.T) {
+	r := LiveRoom{ID: "room", Participants: []Participant{
+		{Username: "host", UserID: "u1", IsTeacher: true},
+		{Username: "a", ID: "g1"},
+		{Username: "a", UserID: "u2"},
+	}}
+	now := time.Now()
+
+	_, err := r.setMuted("user/u1", true)
+	require.Error(t, err)
+	_, err = r.setMuted("nobody", true)
+	require.Error(t, err)
+
+	p, err := r.setMuted("guest/g1", true)
+	require.NoError(t, err)
+	require.Equal(t, "a", p.Username)
+	require.ErrorIs(t, r.allowChat("guest/g1", now), errChatMuted)
+	// the user with the same name is someone else
+	require.NoError(t, r.allowChat("user/u2", now))
+	require.NoError(t, r.allowChat("user/u1", now))
+	require.Error(t, r.allowChat("nobody", now))
+
+	// a muted guest stays muted under another name
+	r.Participants[1].Username = "b"
+	require.ErrorIs(t, r.allowChat("guest/g1", now), errChatMuted)
+
+	_, err = r.setMuted("guest/g1", false)
+	require.NoError(t, err)
+	require.NoError(t, r.allowChat("guest/g1", now))
+}
+
+func TestSlowMode(t *testing.T) {
+	r := LiveRoom{ID: "room", Participants: []Participant{
+		{Username: "host", UserID: "u1", IsTeacher: true},
+		{Username: "a", ID: "g1"},
+	}}
+	now := time.Now()
+
+	require.Error(t, r.setSlowMode(-1))
+	require.Error(t, r.setSlowMode(maxSlowMode+1))
+	require.NoError(t, r.setSlowMode(10))
+
+	// the wait starts only once a message is recorded as sent
+	require.NoError(t, r.allowChat("guest/g1", now))
+	require.NoError(t, r.allowChat("guest/g1", now))
+	r.recordChat("guest/g1", now)
+	err := r.allowChat("guest/g1", now.Add(2500*time.Millisecond))
+	require.EqualError(t, err, "slow mode is on, you can send a message in 8 seconds")
+	require.NoError(t, r.allowChat("guest/g1", now.Add(10*time.Second)))
+
+	r.recordChat("user/u1", now)
+	require.NoError(t, r.allowChat("user/u1", now))
+	require.NotContains(t, r.LastChat, "user/u1")
+
+	require.NoError(t, r.setSlowMode(0))
+	require.NoError(t, r.allowChat("guest/g1", now.Add(10*time.Second)))
+	require.Empty(t, r.LastChat)
+}
+
+func TestPublicChatMsg(t *testing.T) {
+	msg := entities.ChatMsg{ID: "m1", Username: "a", Content: "hello"}
+	require.Equal(t, msg, publicChatMsg(msg))
+
+	msg.Deleted = true
+	require.Empty(t, publicChatMsg(msg).Content)
+	require.Equal(t, "m1", publicChatMsg(msg).ID)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+func TestChatCursor(t *testing.T) {
+	msg := entities.ChatMsg{ID: "m1", CreatedAt: time.Date(2022, 12, 1, 10, 0, 0, 123456000, time.UTC)}
+	before, id, err := decodeChatCursor(encodeChatCursor(msg))
+	require.NoError(t, err)
+	require.True(t, msg.CreatedAt.Equal(before))
+	require.Equal(t, "m1", id)
+
+	before, id, err = decodeChatCursor("")
+	require.NoError(t, err)
+	require.Equal(t, chatCursorEnd, before)
+	require.Empty(t, id)
+
+	for _, cursor := range []string{"%%%", "bm8tc3BhY2U", "eWVzdGVyZGF5IG0x"} {
+		_, _, err := decodeChatCursor(cursor)
+		require.Error(t, err, cursor)
+	}
+}
+
+func TestValidateEmoji(t *testing.T) {
+	for _, emoji := range []string{"👍", "❤️", "👍🏽", "👩‍💻", "🇻🇳"} {
+		require.NoError(t, validateEmoji(emoji), emoji)
+	}
+	for _, emoji := range []string{"", "a", "ok👍", "‍", "👍👍👍👍👍👍👍👍👍"} {
+		require.Error(t, validateEmoji(emoji), emoji)
+	}
+}
+
+func TestChatHistory(t *testing.T) {
+	db, config := newTestStore(t)
+	service := NewSlideService(db, &config)
+
+	sessionID := utils.RandomString(12)
+	slideID := utils.RandomString(12)
+	ids := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		msg, err := service.SaveChatMsg(sessionID, slideID, "a", "hello", "")
+		require.NoError(t, err)
+		ids = append(ids, msg.ID)
+	}
+
+	// replies to a reply go in the thread of the message
+	reply, err := service.SaveChatMsg(sessionID, slideID, "b", "hi", ids[4])
+	require.NoError(t, err)
+	require.Equal(t, ids[4], reply.ParentID)
+	reply, err = service.SaveChatMsg(sessionID, slideID, "a", "hey", reply.ID)
+	require.NoError(t, err)
+	require.Equal(t, ids[4], reply.ParentID)
+	_, err = service.SaveChatMsg(sessionID, slideID, "a", "hey", utils.RandomString(12))
+	require.Error(t, err)
+
+	_, reacted, err := service.ToggleChatReaction(sessionID, ids[4], "a", "👍")
+	require.NoError(t, err)
+	require.True(t, reacted)
+	reactions, reacted, err := service.ToggleChatReaction(sessionID, ids[4], "b", "👍")
+	require.NoError(t, err)
+	require.True(t, reacted)
+	require.Equal(t, []ChatReaction{{Emoji: "👍", Count: 2}}, reactions.Reactions)
+	reactions, reacted, err = service.ToggleChatReaction(sessionID, ids[4], "b", "👍")
+	require.NoError(t, err)
+	require.False(t, reacted)
+	require.Equal(t, []ChatReaction{{Emoji: "👍", Count: 1}}, reactions.Reactions)
+
+	page, err := service.GetChatPage(sessionID, "a", "", 3)
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 3)
+	require.Equal(t, ids[4], page.Messages[0].ID)
+	require.Equal(t, 2, page.Messages[0].ReplyCount)
+	require.Equal(t, []ChatReaction{{Emoji: "👍", Count: 1, Reacted: true}}, page.Messages[0].Reactions)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = service.GetChatPage(sessionID, "a", page.NextCursor, 3)
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 2)
+	require.Equal(t, ids[1], page.Messages[0].ID)
+	require.Equal(t, ids[0], page.Messages[1].ID)
+	require.Empty(t, page.NextCursor)
+
+	replies, err := service.GetChatReplies(sessionID, "a", ids[4])
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	require.Equal(t, reply.ID, replies[1].ID)
+}
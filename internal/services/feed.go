@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+const (
+	// feedBuffer is how many events a feed can fall behind before it is closed,
+	// the client reconnects and starts again from a snapshot
+	feedBuffer = 64
+	// feedKeepAlive keeps proxies from closing an idle feed
+	feedKeepAlive = 15 * time.Second
+)
+
+// feedEvents are the room events a feed carries, with the same payloads as the
+// socket events of docs/socket-protocol.json. The feed is only sent what the
+// audience of the room gets, results reach it once they are revealed.
+var feedEvents = map[string]bool{
+	"getRoomState":     true,
+	"questionTick":     true,
+	"questionClosed":   true,
+	"showStatistic":    true,
+	"scaleStatistic":   true,
+	"rankingStatistic": true,
+	"wordCloud":        true,
+	"leaderboard":      true,
+	"podium":           true,
+	"reactions":        true,
+	"pinnedQuestion":   true,
+	"pacedEnded":       true,
+	// the feed ends after roomClosed
+	"cancelPresentation": true,
+	"roomClosed":         true,
+}
+
+// FeedEvent is a room event encoded for the feeds of the room.
+type FeedEvent struct {
+	Event string
+	Data  []byte
+}
+
+func (ev FeedEvent) ends() bool {
+	return ev.Event == "roomClosed"
+}
+
+// LiveFeed sends the events of a room to its read-only feeds. It sits in front
+// of the socket server of this instance so it gets every room broadcast, the
+// ones of other instances included.
+type LiveFeed struct {
+	lock sync.Mutex
+	// [Room ID] -> feeds
+	feeds map[string]map[chan FeedEvent]struct{}
+}
+
+func NewLiveFeed() *LiveFeed {
+	return &LiveFeed{feeds: make(map[string]map[chan FeedEvent]struct{})}
+}
+
+// subscribe returns the events of the room until unsubscribe is called. The
+// channel is closed when the feed falls behind.
+func (f *LiveFeed) subscribe(roomID string) (<-chan FeedEvent, func()) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	ch := make(chan FeedEvent, feedBuffer)
+	if f.feeds[roomID] == nil {
+		f.feeds[roomID] = make(map[chan FeedEvent]struct{})
+	}
+	f.feeds[roomID][ch] = struct{}{}
+	return ch, func() { f.unsubscribe(roomID, ch) }
+}
+
+func (f *LiveFeed) unsubscribe(roomID string, ch chan FeedEvent) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.feeds[roomID][ch]; !ok {
+		return
+	}
+	f.remove(roomID, ch)
+}
+
+// remove drops a feed, the lock has to be held.
+func (f *LiveFeed) remove(roomID string, ch chan FeedEvent) {
+	delete(f.feeds[roomID], ch)
+	if len(f.feeds[roomID]) == 0 {
+		delete(f.feeds, roomID)
+	}
+	close(ch)
+}
+
+func (f *LiveFeed) publish(roomID, event string, args []interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if len(f.feeds[roomID]) == 0 {
+		return nil
+	}
+	var data interface{}
+	if len(args) > 0 {
+		data = args[0]
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	ev := FeedEvent{Event: event, Data: payload}
+	for ch := range f.feeds[roomID] {
+		select {
+		case ch <- ev:
+		default:
+			f.remove(roomID, ch)
+		}
+	}
+	return nil
+}
+
+// wrap returns a broadcaster that sends to next and to the feeds.
+func (f *LiveFeed) wrap(next Broadcaster) Broadcaster {
+	return feedBroadcaster{next: next, feed: f}
+}
+
+type feedBroadcaster struct {
+	next Broadcaster
+	feed *LiveFeed
+}
+
+var _ Broadcaster = feedBroadcaster{}
+
+func (b feedBroadcaster) BroadcastToRoom(namespace, room, event string, args ...interface{}) bool {
+	ok := b.next.BroadcastToRoom(namespace, room, event, args...)
+	if namespace == "/" && feedEvents[event] {
+		if err := b.feed.publish(room, event, args); err != nil {
+			fmt.Println("publish feed event:", err)
+		}
+	}
+	return ok
+}
+
+type streamFeedRequest struct {
+	Room string `uri:"room" binding:"required"`
+}
+
+// StreamFeed streams the events of a running room as Server-Sent Events, the
+// room is given by its ID or its game PIN. The stream starts with the resume
+// snapshot of a participant that has not answered, without the chat, and the
+// pinned question. Group rooms only stream to members of the group, as they
+// only let them join.
+func (s *PresentationService) StreamFeed(ctx *gin.Context) {
+	var req streamFeedRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse(err))
+		return
+	}
+
+	roomID := req.Room
+	if isRoomPin(roomID) {
+		id, ok, err := s.Rooms.ResolvePin(ctx, roomID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+			return
+		}
+		if !ok {
+			ctx.JSON(http.StatusNotFound, utils.ErrorResponse(fmt.Errorf("game PIN not found")))
+			return
+		}
+		roomID = id
+	}
+
+	r, ok, err := s.Rooms.Get(ctx, roomID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse(ErrRoomNotFound))
+		return
+	}
+	if status, err := s.checkFeedAccess(ctx, r, ctx.GetString(constants.Token_USER_ID)); err != nil {
+		ctx.JSON(status, utils.ErrorResponse(err))
+		return
+	}
+
+	// subscribing first so nothing is missed between the snapshot and the feed
+	events, unsubscribe := s.Feed.subscribe(roomID)
+	defer unsubscribe()
+
+	snapshot, pinned, err := s.feedSnapshot(ctx, roomID)
+	if err == errNotRunning {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse(ErrRoomNotFound))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("resume", snapshot)
+	ctx.SSEvent("pinnedQuestion", pinned)
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(feedKeepAlive)
+	defer keepAlive.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case ev, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(ev.Event, string(ev.Data))
+			return !ev.ends()
+		}
+	})
+}
+
+// checkFeedAccess applies the checks of join to a feed of the room, it returns
+// the status to refuse the feed with.
+func (s *PresentationService) checkFeedAccess(ctx context.Context, r LiveRoom, userID string) (int, error) {
+	if userID != "" && r.Banned[Participant{UserID: userID}.key()] {
+		return http.StatusForbidden, errBanned
+	}
+	if !r.IsGroup {
+		return http.StatusOK, nil
+	}
+	if userID == "" {
+		return http.StatusUnauthorized, errSocketUnauthenticated
+	}
+	isUserInGroup, err := s.DB.CheckUserInGroup(ctx, repositories.CheckUserInGroupParams{
+		GroupID: r.GroupID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("check user in group failed: %w", err)
+	}
+	if !isUserInGroup {
+		return http.StatusForbidden, fmt.Errorf("you are not in the group")
+	}
+	return http.StatusOK, nil
+}
+
+// feedSnapshot returns the state a feed starts from, as encoded JSON.
+func (s *PresentationService) feedSnapshot(ctx context.Context, roomID string) (string, string, error) {
+	snapshot, err := s.Resume(ctx, roomID, Participant{})
+	if err != nil {
+		return "", "", err
+	}
+	snapshot.ChatMsgs = []entities.ChatMsg{}
+
+	r, _, err := s.Rooms.Get(ctx, roomID)
+	if err != nil {
+		return "", "", err
+	}
+	var pinned *entities.UserQuestion
+	if r.PinnedQuestion != "" {
+		question, err := s.DB.GetUserQuestion(ctx, r.PinnedQuestion)
+		if err != nil && err != sql.ErrNoRows {
+			return "", "", err
+		}
+		if err == nil && question.SessionID == r.SessionID && question.Status == constants.UserQuestionStatus_APPROVED {
+			public := publicUserQuestion(question.UserQuestion)
+			pinned = &public
+		}
+	}
+
+	snapshotData, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", "", err
+	}
+	pinnedData, err := json.Marshal(PinnedQuestion{Question: pinned})
+	if err != nil {
+		return "", "", err
+	}
+	return string(snapshotData), string(pinnedData), nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+type nopBroadcaster struct{}
+
+func (nopBroadcaster) BroadcastToRoom(namespace, room, event string, args ...interface{}) bool {
+	return true
+}
+
+func TestLiveFeed(t *testing.T) {
+	feed := NewLiveFeed()
+	broadcaster := feed.wrap(nopBroadcaster{})
+	events, unsubscribe := feed.subscribe("room")
+
+	broadcaster.BroadcastToRoom("/", "room", "getRoomState", RoomState{State: 2})
+	// not for the audience, not a feed event, not the room
+	broadcaster.BroadcastToRoom("/", hostRoom("room"), "showStatistic", AnswerStatistic{})
+	broadcaster.BroadcastToRoom("/", "room", "chat", ChatMessage{Username: "student", Msg: "hi", MsgID: "id"})
+	broadcaster.BroadcastToRoom("/", "other", "getRoomState", RoomState{})
+	// events relayed from other instances are already encoded
+	broadcaster.BroadcastToRoom("/", "room", "reactions", json.RawMessage(`{"question_id":"q"}`))
+
+	require.Equal(t, FeedEvent{Event: "getRoomState", Data: []byte(`{"state":2,"question_id":"","locked":false,"revealed":false}`)}, <-events)
+	require.Equal(t, FeedEvent{Event: "reactions", Data: []byte(`{"question_id":"q"}`)}, <-events)
+	require.Empty(t, events)
+
+	unsubscribe()
+	_, ok := <-events
+	require.False(t, ok)
+	unsubscribe()
+	require.Empty(t, feed.feeds)
+}
+
+func TestLiveFeedClosesSlowFeeds(t *testing.T) {
+	feed := NewLiveFeed()
+	slow, unsubscribe := feed.subscribe("room")
+	defer unsubscribe()
+
+	for i := 0; i <= feedBuffer; i++ {
+		require.NoError(t, feed.publish("room", "questionTick", []interface{}{QuestionTick{}}))
+	}
+	for i := 0; i < feedBuffer; i++ {
+		<-slow
+	}
+	_, ok := <-slow
+	require.False(t, ok)
+}
+
+type fakeFeedStore struct {
+	*fakeResumeStore
+	pinned entities.UserQuestion
+}
+
+func (f *fakeFeedStore) CheckUserInGroup(ctx context.Context, arg repositories.CheckUserInGroupParams) (bool, error) {
+	return arg.UserID == "member", nil
+}
+
+func (f *fakeFeedStore) GetUserQuestion(ctx context.Context, questionID string) (repositories.UserQuestion, error) {
+	return repositories.UserQuestion{UserQuestion: f.pinned}, nil
+}
+
+func TestStreamFeed(t *testing.T) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	sessionID := utils.RandomString(12)
+	store := &fakeFeedStore{
+		fakeResumeStore: &fakeResumeStore{
+			chatMsgs: []entities.ChatMsg{{ID: utils.RandomString(12), SessionID: sessionID}},
+		},
+		pinned: entities.UserQuestion{
+			QuestionID: utils.RandomString(12),
+			Username:   "student",
+			SessionID:  sessionID,
+			Status:     constants.UserQuestionStatus_APPROVED,
+			Anonymous:  true,
+		},
+	}
+
+	rooms := NewRoomManager()
+	config := &utils.Config{}
+	svc := NewPresentationService(store, rooms, NewSlideService(store, config), config)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", SID: "teacher-sid"}, false, "")
+	require.NoError(t, err)
+	pin, err := rooms.AssignPin(ctx, roomID)
+	require.NoError(t, err)
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.SessionID = sessionID
+		r.PinnedQuestion = store.pinned.QuestionID
+		return nil
+	})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	route := gin.New()
+	route.GET("/presentation/feed/:room", svc.StreamFeed)
+	server := httptest.NewServer(route)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/presentation/feed/" + utils.RandomString(12))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = http.Get(server.URL + "/presentation/feed/" + pin)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(res.Body)
+	next := func() (string, string) {
+		var event, data string
+		for {
+			line, err := body.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return event, data
+			case strings.HasPrefix(line, "event:"):
+				event = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				data = strings.TrimPrefix(line, "data:")
+			}
+		}
+	}
+
+	event, data := next()
+	require.Equal(t, "resume", event)
+	var snapshot ResumeSnapshot
+	require.NoError(t, json.Unmarshal([]byte(data), &snapshot))
+	require.Equal(t, sessionID, snapshot.SessionID)
+	require.Empty(t, snapshot.ChatMsgs)
+
+	event, data = next()
+	require.Equal(t, "pinnedQuestion", event)
+	var pinned PinnedQuestion
+	require.NoError(t, json.Unmarshal([]byte(data), &pinned))
+	require.Equal(t, store.pinned.QuestionID, pinned.Question.QuestionID)
+	require.Empty(t, pinned.Question.Username)
+
+	broadcaster := svc.Feed.wrap(nopBroadcaster{})
+	broadcaster.BroadcastToRoom("/", roomID, "getRoomState", RoomState{State: 1})
+	// the podium comes after the end of a student-paced room
+	broadcaster.BroadcastToRoom("/", roomID, "pacedEnded", RoomRef{RoomID: roomID})
+	broadcaster.BroadcastToRoom("/", roomID, "podium", Leaderboard{Entries: []LeaderboardEntry{}})
+	broadcaster.BroadcastToRoom("/", roomID, "roomClosed", RoomRef{RoomID: roomID})
+
+	event, data = next()
+	require.Equal(t, "getRoomState", event)
+	require.JSONEq(t, `{"state":1,"question_id":"","locked":false,"revealed":false}`, data)
+	event, _ = next()
+	require.Equal(t, "pacedEnded", event)
+	event, data = next()
+	require.Equal(t, "podium", event)
+	require.JSONEq(t, `{"entries":[]}`, data)
+	event, data = next()
+	require.Equal(t, "roomClosed", event)
+	require.JSONEq(t, `{"room_id":"`+roomID+`"}`, data)
+	// the stream ends with the room
+	_, err = body.ReadString('\n')
+	require.Error(t, err)
+}
+
+func TestStreamFeedOfGroupRoom(t *testing.T) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	store := &fakeFeedStore{fakeResumeStore: &fakeResumeStore{}}
+	rooms := NewRoomManager()
+	config := &utils.Config{}
+	svc := NewPresentationService(store, rooms, NewSlideService(store, config), config)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", UserID: "member", SID: "teacher-sid"}, true, utils.RandomString(12))
+	require.NoError(t, err)
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.SessionID = utils.RandomString(12)
+		r.Banned = map[string]bool{"user/banned": true}
+		return nil
+	})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	route := gin.New()
+	// the user is set the way AuthOptional sets it
+	route.GET("/presentation/feed/:room", func(ctx *gin.Context) {
+		if user := ctx.Query("user"); user != "" {
+			ctx.Set(constants.Token_USER_ID, user)
+		}
+	}, svc.StreamFeed)
+	server := httptest.NewServer(route)
+	defer server.Close()
+
+	status := func(query string) int {
+		res, err := http.Get(server.URL + "/presentation/feed/" + roomID + query)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+	require.Equal(t, http.StatusUnauthorized, status(""))
+	require.Equal(t, http.StatusForbidden, status("?user=stranger"))
+	require.Equal(t, http.StatusForbidden, status("?user=banned"))
+
+	require.Equal(t, http.StatusOK, status("?user=member"))
+}
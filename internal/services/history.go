@@ -7,12 +7,17 @@ import (
 	"github.com/vtv-us/kahoot-backend/internal/repositories"
 )
 
-func (s *SlideService) SaveAnswerHistory(username, slideID, questionID, answerID string) error {
+// SaveAnswerHistory keeps the answer of the participant with the key, the
+// username is only what it is shown as.
+func (s *SlideService) SaveAnswerHistory(sessionID, participant, username, slideID, questionID, answerID string, points int) error {
 	_, err := s.DB.UpsertAnswerHistory(context.Background(), repositories.UpsertAnswerHistoryParams{
-		Username:   username,
-		SlideID:    slideID,
-		QuestionID: questionID,
-		AnswerID:   answerID,
+		SessionID:   sessionID,
+		Participant: participant,
+		Username:    username,
+		SlideID:     slideID,
+		QuestionID:  questionID,
+		AnswerID:    answerID,
+		Points:      int32(points),
 	})
 	return err
 }
@@ -30,8 +35,24 @@ func (s *SlideService) ListAnswerHistoryBySlideID(slideID string) ([]entities.An
 	return answers, nil
 }
 
-func (s *SlideService) ListAnswerHistoryByQuestionID(questionID string) ([]entities.AnswerHistory, error) {
-	res, err := s.DB.ListAnswerHistoryByQuestionID(context.Background(), questionID)
+func (s *SlideService) ListAnswerHistoryBySessionID(sessionID string) ([]entities.AnswerHistory, error) {
+	res, err := s.DB.ListAnswerHistoryBySessionID(context.Background(), sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]entities.AnswerHistory, 0, len(res))
+	for _, answer := range res {
+		answers = append(answers, answer.AnswerHistory)
+	}
+	return answers, nil
+}
+
+func (s *SlideService) ListAnswerHistoryByQuestionID(sessionID, questionID string) ([]entities.AnswerHistory, error) {
+	res, err := s.DB.ListAnswerHistoryByQuestionID(context.Background(), repositories.ListAnswerHistoryByQuestionIDParams{
+		SessionID:  sessionID,
+		QuestionID: questionID,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -57,12 +78,27 @@ func (s *SlideService) ListAnswerHistoryByAnswerID(answerID string) ([]entities.
 }
 
 type AnswerCount struct {
-	AnswerID string
-	Count    int
+	AnswerID string `json:"answer_id"`
+	Count    int    `json:"count"`
+}
+
+// ResultList is the answers every participant gave to a question.
+type ResultList struct {
+	QuestionID string                   `json:"question_id"`
+	Answers    []entities.AnswerHistory `json:"answers"`
+}
+
+// AnswerStatistic is how many participants picked each answer of a question.
+type AnswerStatistic struct {
+	QuestionID string        `json:"question_id"`
+	Counts     []AnswerCount `json:"counts"`
 }
 
-func (s *SlideService) CountAnswerByQuestionID(questionID string) ([]AnswerCount, error) {
-	res, err := s.DB.CountAnswerByQuestionID(context.Background(), questionID)
+func (s *SlideService) CountAnswerByQuestionID(sessionID, questionID string) ([]AnswerCount, error) {
+	res, err := s.DB.CountAnswerByQuestionID(context.Background(), repositories.CountAnswerByQuestionIDParams{
+		SessionID:  sessionID,
+		QuestionID: questionID,
+	})
 	if err != nil {
 		return nil, err
 	}
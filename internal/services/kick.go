@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+// kickedEvent tells a participant it has been removed, the instance holding
+// its connection closes it right after.
+const kickedEvent = "kicked"
+
+var errBanned = &RoomError{
+	Code:    constants.RoomError_BANNED,
+	Message: "you have been banned from this room",
+}
+
+type KickNotice struct {
+	RoomID string `json:"room_id"`
+	Banned bool   `json:"banned"`
+}
+
+// connRoom is the socket room every connection joins on its own, so it can be
+// reached from any instance.
+func connRoom(sid string) string {
+	return "conn/" + sid
+}
+
+// key identifies the participant across connections, the user for logged in
+// participants and the guest ID for guests.
+func (p Participant) key() string {
+	switch {
+	case p.UserID != "":
+		return "user/" + p.UserID
+	case p.ID != "":
+		return "guest/" + p.ID
+	default:
+		return "name/" + p.Username
+	}
+}
+
+// kick removes a participant from the room and returns it as it was. A banned
+// participant cannot join again for the rest of the session.
+func (r *LiveRoom) kick(key string, ban bool) (Participant, error) {
+	p := r.participant(key)
+	if p == nil {
+		return Participant{}, fmt.Errorf("participant not found")
+	}
+	if p.IsTeacher {
+		return Participant{}, fmt.Errorf("hosts cannot be removed from the room")
+	}
+
+	kicked := *p
+	p.Status = constants.SocketParticipantStatus_LEFT
+	p.SID = ""
+	// a participant who was never admitted has to wait in the lobby again
+	if kicked.Status == constants.SocketParticipantStatus_PENDING {
+		r.removeParticipant(key)
+	}
+	if ban {
+		if r.Banned == nil {
+			r.Banned = make(map[string]bool)
+		}
+		r.Banned[kicked.key()] = true
+	}
+	return kicked, nil
+}
+
+// RecordKick keeps the kick in the report of the session.
+func (s *PresentationService) RecordKick(ctx context.Context, sessionID string, p Participant, banned bool, kickedBy string) error {
+	_, err := s.DB.CreateParticipantKick(ctx, repositories.CreateParticipantKickParams{
+		SessionID:     sessionID,
+		Username:      p.Username,
+		UserID:        p.UserID,
+		ParticipantID: p.ID,
+		Banned:        banned,
+		KickedBy:      kickedBy,
+	})
+	return err
+}
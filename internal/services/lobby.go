@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+// admittedEvent tells a participant it has been let in from the lobby, the
+// client then joins again to enter the room.
+const admittedEvent = "admitted"
+
+// RoomError is a join error the client can tell apart by its code.
+type RoomError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RoomError) Error() string {
+	return e.Message
+}
+
+var errRoomFull = &RoomError{
+	Code:    constants.RoomError_FULL,
+	Message: "the room is full",
+}
+
+type AdmitNotice struct {
+	RoomID string `json:"room_id"`
+}
+
+// full reports whether the audience has reached the cap, participants waiting
+// in the lobby included.
+func (r *LiveRoom) full() bool {
+	if r.MaxParticipants <= 0 {
+		return false
+	}
+	count := 0
+	for _, p := range r.Participants {
+		if p.IsTeacher || p.Status == constants.SocketParticipantStatus_LEFT {
+			continue
+		}
+		count++
+	}
+	return count >= r.MaxParticipants
+}
+
+func (r *LiveRoom) PendingParticipants() []Participant {
+	pending := make([]Participant, 0)
+	for _, p := range r.Participants {
+		if p.Status == constants.SocketParticipantStatus_PENDING {
+			pending = append(pending, p)
+		}
+	}
+	return pending
+}
+
+// admit lets the pending participant with the key in, or every pending
+// participant when key is empty, and returns who was let in.
+func (r *LiveRoom) admit(key string) ([]Participant, error) {
+	admitted := make([]Participant, 0)
+	for i := range r.Participants {
+		p := &r.Participants[i]
+		if p.Status != constants.SocketParticipantStatus_PENDING {
+			continue
+		}
+		if key != "" && p.key() != key {
+			continue
+		}
+		p.Status = constants.SocketParticipantStatus_ACTIVE
+		admitted = append(admitted, *p)
+	}
+	if key != "" && len(admitted) == 0 {
+		return nil, fmt.Errorf("participant is not waiting in the lobby")
+	}
+	return admitted, nil
+}
+
+func (r *LiveRoom) removeParticipant(key string) {
+	for i := range r.Participants {
+		if r.Participants[i].key() == key {
+			r.Participants = append(r.Participants[:i], r.Participants[i+1:]...)
+			return
+		}
+	}
+}
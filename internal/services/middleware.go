@@ -45,6 +45,28 @@ func (c *AuthMiddlewareConfig) AuthRequired(ctx *gin.Context) {
 	ctx.Next()
 }
 
+// AuthOptional sets the user of a valid token like AuthRequired, requests
+// without one go through as anonymous. The token can also be sent as the token
+// query parameter, as EventSource clients cannot set headers.
+func (c *AuthMiddlewareConfig) AuthOptional(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if header := strings.Split(ctx.Request.Header.Get("authorization"), "Bearer "); len(header) >= 2 {
+		token = header[1]
+	}
+	if token == "" {
+		ctx.Next()
+		return
+	}
+
+	res, err := c.auth.JWT.ValidateToken(token)
+	if err == nil {
+		ctx.Set(constants.Token_USER_ID, res.UserID)
+		ctx.Set(constants.Token_EMAIL, res.Email)
+	}
+
+	ctx.Next()
+}
+
 func (c *AuthMiddlewareConfig) CORSMiddleware(ctx *gin.Context) {
 	ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 	ctx.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
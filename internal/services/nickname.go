@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxNicknameLength = 20
+
+// blockedNicknameWords are rejected in a guest nickname, they are matched like
+// the words of containsBlockedWord.
+var blockedNicknameWords = []string{
+	"asshole",
+	"bastard",
+	"bitch*",
+	"cunt",
+	"fag",
+	"faggot",
+	"*fuck*",
+	"nigga",
+	"nigger",
+	"penis",
+	"pussy",
+	"retard",
+	"shit*",
+	"slut",
+	"vagina",
+	"whore",
+}
+
+// nicknameLeet maps the digits and symbols used to get around the word list.
+var nicknameLeet = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"@", "a",
+	"$", "s",
+	"!", "i",
+)
+
+// validateNickname checks the name a guest picked and returns it with its
+// spaces cleaned up.
+func validateNickname(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", fmt.Errorf("please enter your name")
+	}
+	if utf8.RuneCountInString(name) > maxNicknameLength {
+		return "", fmt.Errorf("name must be at most %d characters", maxNicknameLength)
+	}
+	for _, c := range name {
+		if !unicode.IsPrint(c) {
+			return "", fmt.Errorf("name contains invalid characters")
+		}
+	}
+
+	if containsBlockedWord(name, blockedNicknameWords) {
+		return "", fmt.Errorf("this name is not allowed")
+	}
+	return name, nil
+}
+
+// containsBlockedWord reports whether the text has one of the words once it is
+// lowercased and undone of leet speak. Words are matched against whole words
+// of the text, stripped of everything but letters, so a word does not match
+// longer words that contain it. A word starting or ending with * also matches
+// the words that start or end with it.
+func containsBlockedWord(text string, words []string) bool {
+	tokens := strings.FieldsFunc(nicknameLeet.Replace(strings.ToLower(text)), func(c rune) bool {
+		return unicode.IsSpace(c) || c == '-' || c == '_' || c == '/'
+	})
+	for _, token := range tokens {
+		token = strings.Map(func(c rune) rune {
+			if unicode.IsLetter(c) {
+				return c
+			}
+			return -1
+		}, token)
+		if token == "" {
+			continue
+		}
+		for _, word := range words {
+			if matchBlockedWord(token, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchBlockedWord(token, word string) bool {
+	prefix := strings.HasSuffix(word, "*")
+	suffix := strings.HasPrefix(word, "*")
+	word = strings.Trim(word, "*")
+	switch {
+	case word == "":
+		return false
+	case prefix && suffix:
+		return strings.Contains(token, word)
+	case prefix:
+		return strings.HasPrefix(token, word)
+	case suffix:
+		return strings.HasSuffix(token, word)
+	default:
+		return token == word
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNickname(t *testing.T) {
+	name, err := validateNickname("  Anna   Le ")
+	require.NoError(t, err)
+	require.Equal(t, "Anna Le", name)
+
+	for _, name := range []string{
+		"",
+		"   ",
+		"a name that is far too long",
+		"bell\aname",
+		"shithead",
+		"Sh1t Head",
+		"F.u.c.k",
+		"motherfucker",
+		"Fag",
+	} {
+		_, err := validateNickname(name)
+		require.Error(t, err, name)
+	}
+
+	// names that only contain a blocked word are fine
+	for _, name := range []string{"Cassandra", "Scunthorpe", "Penistone", "Fagan"} {
+		_, err := validateNickname(name)
+		require.NoError(t, err, name)
+	}
+}
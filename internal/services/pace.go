@@ -0,0 +1,237 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+var (
+	errNotPaced  = errors.New("the room is not student-paced")
+	errPaced     = errors.New("participants move through the questions themselves in a student-paced room")
+	errPaceEnded = errors.New("the host has ended the session")
+)
+
+// PacedQuestion is a question of a student-paced room, in the order of the slide.
+type PacedQuestion struct {
+	QuestionID string
+	TimeLimit  int
+}
+
+// Pace is where a participant is in a student-paced room. Index is the
+// position of its question, starting at 1 like the room state.
+type Pace struct {
+	Index    int
+	Window   QuestionWindow
+	Finished bool
+}
+
+// PaceState is what a participant sees of its own pace.
+type PaceState struct {
+	Index      int    `json:"index"`
+	Total      int    `json:"total"`
+	QuestionID string `json:"question_id"`
+	// Deadline is zero when the question has no time limit
+	Deadline time.Time `json:"deadline"`
+	Finished bool      `json:"finished"`
+	Ended    bool      `json:"ended"`
+}
+
+// PaceProgress is a row of the progress grid of the host.
+type PaceProgress struct {
+	Username   string `json:"username"`
+	Index      int    `json:"index"`
+	QuestionID string `json:"question_id"`
+	Answered   int    `json:"answered"`
+	Finished   bool   `json:"finished"`
+}
+
+type PaceGrid struct {
+	Total        int            `json:"total"`
+	Ended        bool           `json:"ended"`
+	Participants []PaceProgress `json:"participants"`
+}
+
+func pacedQuestions(questions []repositories.Question) []PacedQuestion {
+	res := make([]PacedQuestion, 0, len(questions))
+	for _, question := range questions {
+		res = append(res, PacedQuestion{
+			QuestionID: question.ID,
+			TimeLimit:  int(question.TimeLimit),
+		})
+	}
+	return res
+}
+
+// setPaced turns student-paced mode on with the questions of the slide, every
+// participant starts at the first one, or off.
+func (r *LiveRoom) setPaced(enabled bool, questions []PacedQuestion, now time.Time) error {
+	if !enabled {
+		r.Paced = false
+		r.PacedQuestions = nil
+		r.PaceEnded = false
+		for i := range r.Participants {
+			r.Participants[i].Pace = Pace{}
+		}
+		return nil
+	}
+	if len(questions) == 0 {
+		return fmt.Errorf("the presentation has no questions")
+	}
+	r.Paced = true
+	r.PacedQuestions = questions
+	r.PaceEnded = false
+	for i := range r.Participants {
+		r.startPace(&r.Participants[i], now)
+	}
+	return nil
+}
+
+// startPace puts a participant at the first question of a student-paced room.
+func (r *LiveRoom) startPace(p *Participant, now time.Time) {
+	p.Pace = Pace{}
+	if !r.Paced || p.IsTeacher {
+		return
+	}
+	r.openPace(p, 1, now)
+}
+
+func (r *LiveRoom) openPace(p *Participant, index int, now time.Time) {
+	question := r.PacedQuestions[index-1]
+	p.Pace.Index = index
+	p.Pace.Window = QuestionWindow{
+		QuestionID: question.QuestionID,
+		OpenedAt:   now,
+	}
+	if question.TimeLimit > 0 {
+		p.Pace.Window.Deadline = now.Add(time.Duration(question.TimeLimit) * time.Second)
+	}
+}
+
+// movePace moves a participant to its next or previous question. Moving on
+// from the last question finishes, the participant cannot go back after that.
+func (r *LiveRoom) movePace(key string, step int, now time.Time) (Participant, error) {
+	if !r.Paced {
+		return Participant{}, errNotPaced
+	}
+	if r.PaceEnded {
+		return Participant{}, errPaceEnded
+	}
+	p := r.participant(key)
+	if p == nil || p.IsTeacher {
+		return Participant{}, errNotInRoom
+	}
+	if p.Pace.Finished {
+		return Participant{}, fmt.Errorf("you have finished all the questions")
+	}
+
+	index := p.Pace.Index + step
+	if index < 1 {
+		return Participant{}, fmt.Errorf("You are at the first question")
+	}
+	if index > len(r.PacedQuestions) {
+		p.Pace.Finished = true
+		p.Pace.Window = QuestionWindow{}
+		return *p, nil
+	}
+	r.openPace(p, index, now)
+	return *p, nil
+}
+
+// endPace ends a student-paced session for everyone.
+func (r *LiveRoom) endPace() error {
+	if !r.Paced {
+		return errNotPaced
+	}
+	r.PaceEnded = true
+	for i := range r.Participants {
+		if !r.Participants[i].IsTeacher {
+			r.Participants[i].Pace.Finished = true
+			r.Participants[i].Pace.Window = QuestionWindow{}
+		}
+	}
+	return nil
+}
+
+// pacedAnswerWindow returns the answering window of a participant for the
+// question, which must be the one the participant is on.
+func pacedAnswerWindow(r LiveRoom, key, questionID string, now time.Time) (QuestionWindow, error) {
+	if r.PaceEnded {
+		return QuestionWindow{}, errPaceEnded
+	}
+	p := r.participant(key)
+	if p == nil {
+		return QuestionWindow{}, errNotInRoom
+	}
+	if p.Pace.Finished || p.Pace.Window.QuestionID != questionID {
+		return QuestionWindow{}, fmt.Errorf("this question is not open for answering")
+	}
+	if !p.Pace.Window.Deadline.IsZero() && now.After(p.Pace.Window.Deadline) {
+		return QuestionWindow{}, errQuestionClosed
+	}
+	return p.Pace.Window, nil
+}
+
+// participantAnswerWindow returns the answering window of the question for a
+// participant, its own in student-paced rooms and the room's otherwise. It
+// reports false when the question is not the current one of a room.
+func participantAnswerWindow(r LiveRoom, key, questionID string, now time.Time) (QuestionWindow, bool, error) {
+	if r.Paced {
+		window, err := pacedAnswerWindow(r, key, questionID, now)
+		return window, err == nil, err
+	}
+	if err := checkQuestionOpen(r, questionID); err != nil {
+		return QuestionWindow{}, false, err
+	}
+	openedAt, deadline, ok := answerWindow(r, questionID)
+	return QuestionWindow{
+		QuestionID: questionID,
+		OpenedAt:   openedAt,
+		Deadline:   deadline,
+	}, ok, nil
+}
+
+func (r LiveRoom) paceState(key string) PaceState {
+	state := PaceState{
+		Total: len(r.PacedQuestions),
+		Ended: r.PaceEnded,
+	}
+	if p := r.participant(key); p != nil {
+		state.Index = p.Pace.Index
+		state.QuestionID = p.Pace.Window.QuestionID
+		state.Deadline = p.Pace.Window.Deadline
+		state.Finished = p.Pace.Finished
+	}
+	return state
+}
+
+// paceGrid is where every participant is, for the host to follow the session.
+func (r LiveRoom) paceGrid() PaceGrid {
+	grid := PaceGrid{
+		Total:        len(r.PacedQuestions),
+		Ended:        r.PaceEnded,
+		Participants: []PaceProgress{},
+	}
+	for _, p := range r.Participants {
+		if p.IsTeacher || p.Status == constants.SocketParticipantStatus_PENDING {
+			continue
+		}
+		answered := 0
+		for _, question := range r.PacedQuestions {
+			if p.Answer[question.QuestionID] != "" {
+				answered++
+			}
+		}
+		grid.Participants = append(grid.Participants, PaceProgress{
+			Username:   p.Username,
+			Index:      p.Pace.Index,
+			QuestionID: p.Pace.Window.QuestionID,
+			Answered:   answered,
+			Finished:   p.Pace.Finished,
+		})
+	}
+	return grid
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+func TestStudentPaced(t *testing.T) {
+	now := time.Now()
+	r := LiveRoom{
+		ID: "room",
+		Participants: []Participant{
+			{Username: "host", IsTeacher: true, Status: constants.SocketParticipantStatus_ACTIVE},
+			{Username: "a", Status: constants.SocketParticipantStatus_ACTIVE},
+		},
+	}
+	_, err := r.movePace("name/a", 1, now)
+	require.ErrorIs(t, err, errNotPaced)
+	require.Error(t, r.setPaced(true, nil, now))
+
+	questions := []PacedQuestion{
+		{QuestionID: "first", TimeLimit: 10},
+		{QuestionID: "second"},
+	}
+	require.NoError(t, r.setPaced(true, questions, now))
+	require.Equal(t, Pace{}, r.Participants[0].Pace)
+	require.Equal(t, PaceState{Index: 1, Total: 2, QuestionID: "first", Deadline: now.Add(10 * time.Second)}, r.paceState("name/a"))
+
+	// participants joining later start at the first question
+	require.NoError(t, r.addParticipant(Participant{Username: "b", SID: "b"}))
+	require.Equal(t, 1, r.participant("name/b").Pace.Index)
+
+	_, err = r.movePace("name/a", -1, now)
+	require.Error(t, err)
+	_, err = pacedAnswerWindow(r, "name/a", "second", now)
+	require.Error(t, err)
+	_, err = pacedAnswerWindow(r, "name/a", "first", now.Add(11*time.Second))
+	require.ErrorIs(t, err, errQuestionClosed)
+	window, err := pacedAnswerWindow(r, "name/a", "first", now)
+	require.NoError(t, err)
+	require.Equal(t, now, window.OpenedAt)
+	require.NoError(t, r.submitAnswer("name/a", "first", "answer"))
+
+	later := now.Add(time.Minute)
+	p, err := r.movePace("name/a", 1, later)
+	require.NoError(t, err)
+	require.Equal(t, 2, p.Pace.Index)
+	require.Equal(t, QuestionWindow{QuestionID: "second", OpenedAt: later}, p.Pace.Window)
+	p, err = r.movePace("name/a", 1, later)
+	require.NoError(t, err)
+	require.True(t, p.Pace.Finished)
+	_, err = r.movePace("name/a", -1, later)
+	require.Error(t, err)
+
+	require.Equal(t, PaceGrid{
+		Total: 2,
+		Participants: []PaceProgress{
+			{Username: "a", Index: 2, Answered: 1, Finished: true},
+			{Username: "b", Index: 1, QuestionID: "first"},
+		},
+	}, r.paceGrid())
+
+	require.NoError(t, r.endPace())
+	require.True(t, r.participant("name/b").Pace.Finished)
+	_, err = r.movePace("name/b", 1, later)
+	require.ErrorIs(t, err, errPaceEnded)
+	_, err = pacedAnswerWindow(r, "name/b", "first", later)
+	require.ErrorIs(t, err, errPaceEnded)
+
+	require.NoError(t, r.setPaced(false, nil, later))
+	require.False(t, r.Paced)
+	require.Equal(t, Pace{}, r.participant("name/a").Pace)
+}
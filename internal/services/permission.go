@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/vtv-us/kahoot-backend/internal/constants"
@@ -69,6 +70,24 @@ func checkSlidePermission(ctx *gin.Context, db repositories.Store, slideID strin
 	return nil
 }
 
+// checkPresentPermission checks that the user owns or collaborates on the slide
+// before hosting it.
+func checkPresentPermission(ctx context.Context, db repositories.Store, slideID, userID string) error {
+	isAllowed, err := db.CheckSlidePermission(ctx, repositories.CheckSlidePermissionParams{
+		ID:    slideID,
+		Owner: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !isAllowed {
+		return fmt.Errorf("only the owner and collaborators can present this slide")
+	}
+
+	return nil
+}
+
 func checkAnswerPermission(ctx *gin.Context, db repositories.Store, answerID string) error {
 	userID := ctx.GetString(constants.Token_USER_ID)
 
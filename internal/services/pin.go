@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+const (
+	minPinLength = 6
+	maxPinLength = 7
+	// attempts per PIN length before falling back to a longer PIN
+	pinAttempts = 20
+)
+
+var ErrNoPinAvailable = errors.New("no game PIN available, try again later")
+
+func randomPin(length int) string {
+	min := int64(1)
+	for i := 1; i < length; i++ {
+		min *= 10
+	}
+	return strconv.FormatInt(utils.RandomInt(min, min*10-1), 10)
+}
+
+func isRoomPin(id string) bool {
+	if len(id) < minPinLength || len(id) > maxPinLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type resolvePinRequest struct {
+	Pin string `uri:"pin" binding:"required,numeric"`
+}
+
+type resolvePinResponse struct {
+	Pin    string `json:"pin"`
+	RoomID string `json:"room_id"`
+}
+
+func (s *PresentationService) ResolvePin(ctx *gin.Context) {
+	var req resolvePinRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse(err))
+		return
+	}
+
+	roomID, ok, err := s.Rooms.ResolvePin(ctx, req.Pin)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse(fmt.Errorf("game PIN not found")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resolvePinResponse{
+		Pin:    req.Pin,
+		RoomID: roomID,
+	})
+}
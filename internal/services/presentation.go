@@ -0,0 +1,24 @@
+package services
+
+import (
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+type PresentationService struct {
+	DB     repositories.Store
+	Config *utils.Config
+	Rooms  SessionStore
+	Slides *SlideService
+	Feed   *LiveFeed
+}
+
+func NewPresentationService(db repositories.Store, rooms SessionStore, slides *SlideService, c *utils.Config) *PresentationService {
+	return &PresentationService{
+		DB:     db,
+		Config: c,
+		Rooms:  rooms,
+		Slides: slides,
+		Feed:   NewLiveFeed(),
+	}
+}
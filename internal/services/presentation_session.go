@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+type presentationSessionResponse struct {
+	ID        string     `json:"id"`
+	SlideID   string     `json:"slide_id"`
+	Host      string     `json:"host"`
+	GroupID   string     `json:"group_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+}
+
+func newPresentationSessionResponse(session entities.PresentationSession) presentationSessionResponse {
+	res := presentationSessionResponse{
+		ID:        session.ID,
+		SlideID:   session.SlideID,
+		Host:      session.Host,
+		GroupID:   session.GroupID,
+		StartedAt: session.StartedAt,
+	}
+	if session.EndedAt.Valid {
+		res.EndedAt = &session.EndedAt.Time
+	}
+	return res
+}
+
+// StartSession records a new presentation of the slide, answers, chat and
+// questions of the audience are kept per session.
+func (s *PresentationService) StartSession(ctx context.Context, sessionID, slideID, host, groupID string) error {
+	_, err := s.DB.CreatePresentationSession(ctx, repositories.CreatePresentationSessionParams{
+		ID:      sessionID,
+		SlideID: slideID,
+		Host:    host,
+		GroupID: groupID,
+	})
+	return err
+}
+
+// ensureSession starts a session for a room that is not running one yet.
+func (s *PresentationService) ensureSession(ctx context.Context, roomID, host, groupID string) error {
+	sessionID, started := uuid.NewString(), false
+	_, err := s.Rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		started = r.SessionID == ""
+		if started {
+			r.SessionID = sessionID
+		}
+		return nil
+	})
+	if err != nil || !started {
+		return err
+	}
+	return s.StartSession(ctx, sessionID, roomID, host, groupID)
+}
+
+// EndSessions marks the running session of the slide as ended.
+func (s *PresentationService) EndSessions(ctx context.Context, slideID string) error {
+	return s.DB.EndPresentationSessionsBySlide(ctx, slideID)
+}
+
+type listSessionBySlideIDRequest struct {
+	SlideID string `uri:"slide_id" binding:"required"`
+}
+
+func (s *PresentationService) ListSessionBySlideID(ctx *gin.Context) {
+	var req listSessionBySlideIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse(err))
+		return
+	}
+
+	if err := checkSlidePermission(ctx, s.DB, req.SlideID); err != nil {
+		ctx.JSON(http.StatusForbidden, utils.ErrorResponse(err))
+		return
+	}
+
+	sessions, err := s.DB.ListPresentationSessionsBySlide(ctx, req.SlideID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+
+	res := make([]presentationSessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, newPresentationSessionResponse(session.PresentationSession))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+type getSessionResultRequest struct {
+	SessionID string `uri:"session_id" binding:"required"`
+}
+
+type sessionResultResponse struct {
+	Session       presentationSessionResponse  `json:"session"`
+	Answers       []entities.AnswerHistory     `json:"answers"`
+	TextAnswers   []entities.TextAnswer        `json:"text_answers"`
+	Selections    []entities.AnswerSelection   `json:"selections"`
+	Leaderboard   []LeaderboardEntry           `json:"leaderboard"`
+	ChatMsgs      []entities.ChatMsg           `json:"chat_msgs"`
+	UserQuestions []entities.UserQuestion      `json:"user_questions"`
+	Kicks         []entities.ParticipantKick   `json:"kicks"`
+	TeamMembers   []entities.SessionTeamMember `json:"team_members"`
+	Teams         []TeamLeaderboardEntry       `json:"teams"`
+	Reactions     []entities.ReactionTotal     `json:"reactions"`
+}
+
+func (s *PresentationService) GetSessionResult(ctx *gin.Context) {
+	var req getSessionResultRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse(err))
+		return
+	}
+
+	session, err := s.DB.GetPresentationSession(ctx, req.SessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, utils.ErrorResponse(fmt.Errorf("session not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+
+	if err := checkSlidePermission(ctx, s.DB, session.SlideID); err != nil {
+		ctx.JSON(http.StatusForbidden, utils.ErrorResponse(err))
+		return
+	}
+
+	res := sessionResultResponse{
+		Session:       newPresentationSessionResponse(session.PresentationSession),
+		Answers:       []entities.AnswerHistory{},
+		TextAnswers:   []entities.TextAnswer{},
+		Selections:    []entities.AnswerSelection{},
+		ChatMsgs:      []entities.ChatMsg{},
+		UserQuestions: []entities.UserQuestion{},
+		Kicks:         []entities.ParticipantKick{},
+		TeamMembers:   []entities.SessionTeamMember{},
+		Reactions:     []entities.ReactionTotal{},
+	}
+
+	answers, err := s.DB.ListAnswerHistoryBySessionID(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, answer := range answers {
+		res.Answers = append(res.Answers, answer.AnswerHistory)
+	}
+
+	textAnswers, err := s.DB.ListTextAnswersBySession(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, answer := range textAnswers {
+		res.TextAnswers = append(res.TextAnswers, answer.TextAnswer)
+	}
+
+	selections, err := s.DB.ListAnswerSelectionsBySession(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, selection := range selections {
+		res.Selections = append(res.Selections, selection.AnswerSelection)
+	}
+
+	scores, err := s.DB.GetLeaderboardBySessionID(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	res.Leaderboard = rankLeaderboard(scores)
+
+	chatMsgs, err := s.DB.GetChatBySession(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, msg := range chatMsgs {
+		res.ChatMsgs = append(res.ChatMsgs, msg.ChatMsg)
+	}
+
+	questions, err := s.DB.ListUserQuestionBySession(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, question := range questions {
+		res.UserQuestions = append(res.UserQuestions, question.UserQuestion)
+	}
+
+	kicks, err := s.DB.ListParticipantKicksBySession(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, kick := range kicks {
+		res.Kicks = append(res.Kicks, kick.ParticipantKick)
+	}
+
+	members, err := s.DB.ListSessionTeamMembers(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, member := range members {
+		res.TeamMembers = append(res.TeamMembers, member.SessionTeamMember)
+	}
+
+	teams, err := s.DB.GetTeamLeaderboardBySessionID(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	res.Teams = rankTeamLeaderboard(teams)
+
+	reactions, err := s.DB.ListReactionTotalsBySession(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
+		return
+	}
+	for _, reaction := range reactions {
+		res.Reactions = append(res.Reactions, reaction.ReactionTotal)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+// The socket protocol is described by docs/socket-protocol.json, generated
+// from the event specs with `make socketspec`. Clients send the version they
+// speak as the protocol query parameter of the handshake, connections without
+// one are refused. Version 1, with positional arguments and errors as plain
+// text, is no longer spoken.
+//
+// An event carries at most one payload, a struct with snake_case fields.
+// Errors are sent as SocketError with a code to tell them apart.
+const (
+	ProtocolVersion    = 2
+	minProtocolVersion = 2
+)
+
+// ProtocolInfo is sent as protocol once a connection is made.
+type ProtocolInfo struct {
+	Version    int `json:"version"`
+	MinVersion int `json:"min_version"`
+	MaxVersion int `json:"max_version"`
+}
+
+// Ack is what the acknowledgement callback of every event gets. Data is the
+// payload of the first event sent back to the caller, when there is one.
+type Ack struct {
+	OK      bool        `json:"ok"`
+	Code    string      `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// negotiateProtocol returns the version of the protocol the connection asked
+// for in its handshake.
+func negotiateProtocol(s socketio.Conn) (int, error) {
+	u := s.URL()
+	v := u.Query().Get("protocol")
+	if v == "" {
+		return 0, fmt.Errorf("send the protocol query parameter, the server speaks %d to %d", minProtocolVersion, ProtocolVersion)
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil || version < minProtocolVersion || version > ProtocolVersion {
+		return 0, fmt.Errorf("unsupported protocol version %s, the server speaks %d to %d", v, minProtocolVersion, ProtocolVersion)
+	}
+	return version, nil
+}
+
+// errorCodes are the codes of the errors clients can act on, any other error
+// has the failed code.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{errSocketUnauthenticated, constants.SocketError_UNAUTHENTICATED},
+	{errNotTeacher, constants.SocketError_FORBIDDEN},
+	{errNotInRoom, constants.SocketError_NOT_IN_ROOM},
+	{ErrRoomNotFound, constants.SocketError_ROOM_NOT_FOUND},
+	{errNotRunning, constants.SocketError_NOT_RUNNING},
+	{errNoQuestion, constants.SocketError_NO_QUESTION},
+	{errQuestionClosed, constants.SocketError_QUESTION_CLOSED},
+	{errAnswersLocked, constants.SocketError_ANSWERS_LOCKED},
+	{errChatMuted, constants.SocketError_MUTED},
+	{errChatBlocked, constants.SocketError_BLOCKED_WORDS},
+}
+
+func errorCode(err error) string {
+	var roomErr *RoomError
+	if errors.As(err, &roomErr) {
+		return roomErr.Code
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return constants.SocketError_FAILED
+}
+
+// emitError sends the error of an event to the connection and to the
+// acknowledgement of the event.
+func emitError(s socketio.Conn, err error) {
+	socketErr := SocketError{
+		Code:    errorCode(err),
+		Message: err.Error(),
+	}
+	if c, ok := s.(*ackConn); ok {
+		socketErr.Event = c.event
+		c.fail(socketErr)
+	}
+	s.Emit("error", socketErr)
+}
+
+// ackConn is the connection handed to an event handler, it keeps what the
+// handler sent back to build the acknowledgement of the event.
+type ackConn struct {
+	socketio.Conn
+	event string
+	ack   Ack
+	// replied is set once the handler sent an event back
+	replied bool
+}
+
+func newAckConn(s socketio.Conn, event string) *ackConn {
+	return &ackConn{Conn: s, event: event, ack: Ack{OK: true}}
+}
+
+func (c *ackConn) Emit(event string, args ...interface{}) {
+	if event != "error" && !c.replied {
+		c.replied = true
+		if len(args) > 0 {
+			c.ack.Data = args[0]
+		}
+	}
+	c.Conn.Emit(event, args...)
+}
+
+func (c *ackConn) fail(err SocketError) {
+	if !c.ack.OK {
+		return
+	}
+	c.ack = Ack{Code: err.Code, Message: err.Message}
+}
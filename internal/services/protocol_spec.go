@@ -0,0 +1,329 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+)
+
+// EventSpec is an event of the socket protocol, Payload is a value of the Go
+// type of its only argument, nil when the event has none.
+type EventSpec struct {
+	Namespace   string
+	Name        string
+	Description string
+	Payload     interface{}
+}
+
+// clientEvents are the events clients send, every handler has to be listed
+// here with its payload.
+var clientEvents = []EventSpec{
+	{"/", "getRoomActive", "Lists the IDs of the rooms running, sent back as getRoomActive.", nil},
+	{"/", "getActiveParticipants", "Lists the participants connected to the room, sent back as getActiveParticipants.", nil},
+	{"/", "manualDisconnect", "Closes the connection.", nil},
+	{"/", "host", "Starts or rejoins the room of a slide as its host, the user of the token.", HostRequest{}},
+	{"/", "resume", "Sends back the whole state of the room as resume.", nil},
+	{"/", "getRoomState", "Sends back the question the room is on as getRoomState.", nil},
+	{"/", "kickParticipant", "Host only. Removes a participant, with ban it cannot join again for the session.", KickRequest{}},
+	{"/", "lockAnswers", "Host only. Stops answering the current question.", nil},
+	{"/", "unlockAnswers", "Host only. Lets the audience answer the current question again.", nil},
+	{"/", "revealResults", "Host only. Shows the results of the current question to the audience.", nil},
+	{"/", "hideResults", "Host only. Hides the results of the current question from the audience.", nil},
+	{"/", "setRoomState", "Host only. Moves the room to the question at state.", SetRoomStateRequest{}},
+	{"/", "next", "Host only. Moves the room to the next question.", nil},
+	{"/", "prev", "Host only. Moves the room to the previous question.", nil},
+	{"/", "setQuizMode", "Host only. Turns scoring on or off.", Toggle{}},
+	{"/", "endQuiz", "Host only. Ends the quiz and sends the podium.", nil},
+	{"/", "setPacedMode", "Host only. Lets every participant go through the questions at its own pace.", Toggle{}},
+	{"/", "paceNext", "Moves the participant to its next question in a student-paced room.", nil},
+	{"/", "pacePrev", "Moves the participant to its previous question in a student-paced room.", nil},
+	{"/", "getPaceState", "Sends back where the participant is as paceState.", nil},
+	{"/", "getPaceProgress", "Host only. Sends back where everyone is as paceProgress.", nil},
+	{"/", "endPacedSession", "Host only. Ends a student-paced session.", nil},
+	{"/", "setTeamMode", "Host only. Plays in teams, mode is auto or pick, or empty to stop.", TeamModeRequest{}},
+	{"/", "pickTeam", "Moves the participant to a team in rooms where teams are picked. Once the questions have started only participants without a team can pick.", PickTeamRequest{}},
+	{"/", "getTeams", "Sends back the teams as teams.", nil},
+	{"/", "getTeamLeaderboard", "Sends back the team scores as teamLeaderboard.", nil},
+	{"/", "join", "Joins a room, guests pick a username and keep their place with the participant token.", JoinRequest{}},
+	{"/", "admitParticipant", "Host only. Lets a participant in from the lobby.", ParticipantRef{}},
+	{"/", "admitAll", "Host only. Lets everyone in from the lobby.", nil},
+	{"/", "setLobby", "Host only. Turns the lobby on or off.", Toggle{}},
+	{"/", "getLobby", "Host only. Sends back the participants waiting as getLobby.", nil},
+	{"/", "setMaxParticipants", "Host only. Caps the participants of the room, zero for the default.", MaxParticipantsRequest{}},
+	{"/", "cancelPresentation", "Host only. Stops the presentation of the room.", CancelPresentationRequest{}},
+	{"/", "getSlidePresentation", "Sends back the slide a group is presenting as getSlidePresentation.", GroupRef{}},
+	{"/", "submitAnswer", "Answers a question with an answer ID, the answer IDs of a selection or the value of a scale.", SubmitAnswerRequest{}},
+	{"/", "showStatistic", "Sends the statistic of a question to the room.", QuestionRef{}},
+	{"/", "submitTextAnswer", "Answers a paragraph question.", TextAnswerRequest{}},
+	{"/", "showWordCloud", "Sends the word cloud of a paragraph question to the room.", QuestionRef{}},
+	{"/", "setWordStemming", "Host only. Groups words by their stem in word clouds.", Toggle{}},
+	{"/", "chat", "Sends a message to the room, or a reply to the message parent_id.", ChatRequest{}},
+	{"/", "deleteChat", "Host only. Hides a message from the chat.", MsgRef{}},
+	{"/", "muteParticipant", "Host only. Stops or lets a participant chat.", MuteRequest{}},
+	{"/", "setSlowMode", "Host only. Makes participants wait seconds between messages, zero to stop.", SlowMode{}},
+	{"/", "getChatHistory", "Sends back the page of messages before the cursor as chatHistory.", ChatHistoryRequest{}},
+	{"/", "getChatReplies", "Sends back the replies to a message as chatReplies.", ChatRepliesRequest{}},
+	{"/", "reactChat", "Adds an emoji reaction to a message or takes it back.", ReactChatRequest{}},
+	{"/", "react", "Sends a live reaction to the presenter screen.", ReactRequest{}},
+	{"/", "postQuestion", "Asks the hosts a question.", AskQuestionRequest{}},
+	{"/", "listUserQuestion", "Sends back the questions of the audience as listUserQuestion, sort is top, newest or answered.", ListUserQuestionRequest{}},
+	{"/", "upvoteQuestion", "Votes for a question of the audience or takes the vote back.", QuestionRef{}},
+	{"/", "toggleUserQuestionAnswered", "Host only. Marks a question of the audience answered or not.", QuestionRef{}},
+	{"/", "setQuestionModeration", "Host only. Makes questions of the audience wait for approval.", Toggle{}},
+	{"/", "listPendingQuestions", "Host only. Sends back the questions waiting for approval as listPendingQuestions.", nil},
+	{"/", "approveQuestion", "Host only. Shows a question waiting for approval to the room.", QuestionRef{}},
+	{"/", "rejectQuestion", "Host only. Rejects a question waiting for approval.", QuestionRef{}},
+	{"/", "editQuestion", "Host only. Changes the text of a question of the audience.", EditQuestionRequest{}},
+	{"/", "pinQuestion", "Host only. Puts a question on the presenter screen, an empty ID takes it down.", QuestionRef{}},
+	{"/notification", "join", "Gets the presentations started in the groups of the user.", TokenRequest{}},
+}
+
+// serverEvents are the events the server sends.
+var serverEvents = []EventSpec{
+	{"/", "protocol", "The version of the protocol the connection speaks, sent once connected.", ProtocolInfo{}},
+	{"/", "error", "An event failed.", SocketError{}},
+	{"/", "eventError", "An event was over its rate limit or had an invalid payload and was not handled.", SocketError{}},
+	{"/", "notify", "A message for the user.", Notice{}},
+	{"/", "getRoomActive", "", RoomList{}},
+	{"/", "getActiveParticipants", "", ParticipantList{}},
+	{"/", "gamePin", "", GamePin{}},
+	{"/", "resume", "", ResumeSnapshot{}},
+	{"/", "getRoomState", "The question the room is on, sent whenever it changes.", RoomState{}},
+	{"/", "questionTick", "The time left on the current question, every second.", QuestionTick{}},
+	{"/", "questionClosed", "", QuestionClosed{}},
+	{"/", "showStatistic", "The answer counts of a question.", AnswerStatistic{}},
+	{"/", "scaleStatistic", "The answers of a scale question.", ScaleStatistic{}},
+	{"/", "rankingStatistic", "The average ranks of a ranking question.", RankingStatistic{}},
+	{"/", "resultList", "The answers of a question, to hosts.", ResultList{}},
+	{"/", "wordCloud", "", WordCloud{}},
+	{"/", "wordStemming", "", Toggle{}},
+	{"/", "quizMode", "", Toggle{}},
+	{"/", "leaderboard", "", Leaderboard{}},
+	{"/", "podium", "", Leaderboard{}},
+	{"/", "teams", "", TeamState{}},
+	{"/", "teamLeaderboard", "", TeamLeaderboard{}},
+	{"/", "pacedMode", "", Toggle{}},
+	{"/", "paceState", "", PaceState{}},
+	{"/", "paceProgress", "Where everyone is in a student-paced room, to hosts.", PaceGrid{}},
+	{"/", "pacedEnded", "", RoomRef{}},
+	{"/", "joinRejected", "", RoomError{}},
+	{"/", "participantToken", "", ParticipantToken{}},
+	{"/", "lobby", "The participant waits in the lobby of the room.", RoomRef{}},
+	{"/", admittedEvent, "", AdmitNotice{}},
+	{"/", "getLobby", "", ParticipantList{}},
+	{"/", kickedEvent, "", KickNotice{}},
+	{"/", "participantKicked", "", ParticipantInfo{}},
+	{"/", "cancelPresentation", "", RoomRef{}},
+	{"/", "roomClosed", "The room is gone, after the presentation is stopped or everyone has left.", RoomRef{}},
+	{"/", "getSlidePresentation", "", RoomRef{}},
+	{"/", "chat", "A new message, parent_id is set on replies.", ChatMessage{}},
+	{"/", "chatDeleted", "", MsgRef{}},
+	{"/", "chatHistory", "", ChatPage{}},
+	{"/", "chatReplies", "", ChatReplies{}},
+	{"/", "chatReacted", "Whether the reaction of the participant was added or taken back.", ChatReacted{}},
+	{"/", "chatReaction", "The reactions of a message after one changed.", ChatReactions{}},
+	{"/", "muted", "", ParticipantMuted{}},
+	{"/", "participantMuted", "", ParticipantMuted{}},
+	{"/", "slowMode", "", SlowMode{}},
+	{"/", "reactions", "The live reactions of the last half second.", LiveReactions{}},
+	{"/", "postQuestion", "", entities.UserQuestion{}},
+	{"/", "listUserQuestion", "", UserQuestionList{}},
+	{"/", "upvoteQuestion", "", entities.UserQuestion{}},
+	{"/", "questionVoted", "", UserQuestionItem{}},
+	{"/", "toggleUserQuestionAnswered", "", entities.UserQuestion{}},
+	{"/", "questionModeration", "", Toggle{}},
+	{"/", "listPendingQuestions", "", PendingQuestions{}},
+	{"/", "questionPending", "The question of the participant waits for approval.", entities.UserQuestion{}},
+	{"/", "pendingQuestion", "A question waits for approval, to hosts.", entities.UserQuestion{}},
+	{"/", "questionModerated", "", entities.UserQuestion{}},
+	{"/", "questionRemoved", "", QuestionRef{}},
+	{"/", "questionEdited", "", entities.UserQuestion{}},
+	{"/", "pinnedQuestion", "The question on the presenter screen, null when none.", PinnedQuestion{}},
+	{"/notification", "notify", "A group of the user started presenting.", PresentationNotification{}},
+}
+
+func clientEventSpec(namespace, event string) (EventSpec, bool) {
+	for _, spec := range clientEvents {
+		if spec.Namespace == namespace && spec.Name == event {
+			return spec, true
+		}
+	}
+	return EventSpec{}, false
+}
+
+// checkEventSpec makes sure the handler of an event takes the payload of its
+// spec, so the spec cannot drift from the handlers.
+func checkEventSpec(namespace, event string, f interface{}) error {
+	spec, ok := clientEventSpec(namespace, event)
+	if !ok {
+		return fmt.Errorf("event %s%s has no spec", namespace, event)
+	}
+	ft := reflect.TypeOf(f)
+	if spec.Payload == nil {
+		if ft.NumIn() != 1 {
+			return fmt.Errorf("event %s%s takes a payload, its spec has none", namespace, event)
+		}
+		return nil
+	}
+	if ft.NumIn() != 2 {
+		return fmt.Errorf("event %s%s takes %d arguments, its spec has one payload", namespace, event, ft.NumIn()-1)
+	}
+	if t := reflect.TypeOf(spec.Payload); ft.In(1) != t {
+		return fmt.Errorf("payload of event %s%s is %s, its spec says %s", namespace, event, ft.In(1), t)
+	}
+	return nil
+}
+
+// ProtocolSpec is the machine-readable spec of the socket protocol, with the
+// payloads of the events as JSON schemas.
+type ProtocolSpec struct {
+	Version      int                   `json:"version"`
+	MinVersion   int                   `json:"min_version"`
+	Handshake    string                `json:"handshake"`
+	Ack          JSONSchema            `json:"ack"`
+	ErrorCodes   []string              `json:"error_codes"`
+	ClientEvents []EventSchema         `json:"client_events"`
+	ServerEvents []EventSchema         `json:"server_events"`
+	Definitions  map[string]JSONSchema `json:"definitions"`
+}
+
+// EventSchema is an event, Payload is left out when the event has none.
+type EventSchema struct {
+	Namespace   string     `json:"namespace"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Payload     JSONSchema `json:"payload,omitempty"`
+}
+
+type JSONSchema map[string]interface{}
+
+// NewProtocolSpec builds the spec from the Go types of the events.
+func NewProtocolSpec() ProtocolSpec {
+	defs := make(map[string]JSONSchema)
+	spec := ProtocolSpec{
+		Version:    ProtocolVersion,
+		MinVersion: minProtocolVersion,
+		Handshake:  "Send the version as the protocol query parameter, connections without one are refused.",
+		Ack:        typeSchema(reflect.TypeOf(Ack{}), defs),
+		ErrorCodes: socketErrorCodes(),
+	}
+	spec.ClientEvents = eventSchemas(clientEvents, defs)
+	spec.ServerEvents = eventSchemas(serverEvents, defs)
+	spec.Definitions = defs
+	return spec
+}
+
+func socketErrorCodes() []string {
+	codes := []string{
+		constants.SocketError_RATE_LIMITED,
+		constants.SocketError_INVALID_ARGUMENT,
+		constants.RoomError_FULL,
+		constants.RoomError_BANNED,
+	}
+	for _, c := range errorCodes {
+		codes = append(codes, c.code)
+	}
+	return append(codes, constants.SocketError_FAILED)
+}
+
+func eventSchemas(specs []EventSpec, defs map[string]JSONSchema) []EventSchema {
+	res := make([]EventSchema, 0, len(specs))
+	for _, spec := range specs {
+		event := EventSchema{
+			Namespace:   spec.Namespace,
+			Name:        spec.Name,
+			Description: spec.Description,
+		}
+		if spec.Payload != nil {
+			event.Payload = typeSchema(reflect.TypeOf(spec.Payload), defs)
+		}
+		res = append(res, event)
+	}
+	return res
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeSchema returns the JSON schema of how encoding/json writes the type,
+// structs go to defs and are referenced by name.
+func typeSchema(t reflect.Type, defs map[string]JSONSchema) JSONSchema {
+	switch {
+	case t == timeType:
+		return JSONSchema{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Ptr:
+		return JSONSchema{"anyOf": []JSONSchema{typeSchema(t.Elem(), defs), {"type": "null"}}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return JSONSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{"type": "number"}
+	case reflect.String:
+		return JSONSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return JSONSchema{"type": "string", "contentEncoding": "base64"}
+		}
+		return JSONSchema{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return JSONSchema{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		name := definitionName(t)
+		if _, ok := defs[name]; !ok {
+			// taken before the fields so types can refer to themselves
+			defs[name] = JSONSchema{}
+			props := JSONSchema{}
+			required := []string{}
+			structProperties(t, defs, props, &required)
+			defs[name] = JSONSchema{"type": "object", "properties": props, "required": required}
+		}
+		return JSONSchema{"$ref": "#/definitions/" + name}
+	default:
+		return JSONSchema{}
+	}
+}
+
+// structProperties adds the fields of a struct, and of the structs it embeds,
+// as encoding/json names them.
+func structProperties(t reflect.Type, defs map[string]JSONSchema, props JSONSchema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("json") == "-" || !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && f.Type.Kind() == reflect.Struct {
+			structProperties(f.Type, defs, props, required)
+			continue
+		}
+		name, opts := jsonName(f)
+		props[name] = typeSchema(f.Type, defs)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// jsonName returns the name encoding/json gives a field and the options of its
+// tag.
+func jsonName(f reflect.StructField) (string, string) {
+	name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, opts
+}
+
+// definitionName names a struct by its package where two packages have types
+// of the same name, as entities and services do.
+func definitionName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	return pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name()
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"testing"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+// fakeConn is a connection that keeps what is sent to it.
+type fakeConn struct {
+	socketio.Conn
+	url     url.URL
+	ctx     interface{}
+	emitted []string
+	args    [][]interface{}
+}
+
+func (c *fakeConn) ID() string                 { return "sid" }
+func (c *fakeConn) URL() url.URL               { return c.url }
+func (c *fakeConn) Context() interface{}       { return c.ctx }
+func (c *fakeConn) SetContext(ctx interface{}) { c.ctx = ctx }
+
+func (c *fakeConn) Emit(event string, args ...interface{}) {
+	c.emitted = append(c.emitted, event)
+	c.args = append(c.args, args)
+}
+
+func TestNegotiateProtocol(t *testing.T) {
+	conn := func(query string) *fakeConn {
+		return &fakeConn{url: url.URL{Path: "/socket.io/", RawQuery: query}}
+	}
+
+	version, err := negotiateProtocol(conn("protocol=2"))
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	// clients of the positional version 1 are refused
+	for _, query := range []string{"", "protocol=1", "protocol=0", "protocol=3", "protocol=v2"} {
+		_, err = negotiateProtocol(conn(query))
+		require.Error(t, err, query)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	require.Equal(t, constants.SocketError_FORBIDDEN, errorCode(errNotTeacher))
+	require.Equal(t, constants.SocketError_NOT_IN_ROOM, errorCode(fmt.Errorf("chat: %w", errNotInRoom)))
+	require.Equal(t, constants.RoomError_BANNED, errorCode(&RoomError{Code: constants.RoomError_BANNED}))
+	require.Equal(t, constants.SocketError_FAILED, errorCode(fmt.Errorf("database is down")))
+
+	// every code can be found in the spec
+	codes := socketErrorCodes()
+	for _, c := range errorCodes {
+		require.Contains(t, codes, c.code)
+	}
+}
+
+func TestEmitError(t *testing.T) {
+	conn := &fakeConn{ctx: &RoomContext{Protocol: 2}}
+	c := newAckConn(conn, "next")
+	emitError(c, errNotTeacher)
+	require.Equal(t, []string{"error"}, conn.emitted)
+	require.Equal(t, SocketError{
+		Code:    constants.SocketError_FORBIDDEN,
+		Event:   "next",
+		Message: errNotTeacher.Error(),
+	}, conn.args[0][0])
+	require.Equal(t, Ack{Code: constants.SocketError_FORBIDDEN, Message: errNotTeacher.Error()}, c.ack)
+
+	// only the first error is acknowledged
+	emitError(c, errNotRunning)
+	require.Equal(t, constants.SocketError_FORBIDDEN, c.ack.Code)
+}
+
+func TestAckConn(t *testing.T) {
+	conn := &fakeConn{ctx: &RoomContext{}}
+	c := newAckConn(conn, "getTeams")
+	c.Emit("teams", "a")
+	c.Emit("teams", "b")
+	require.Equal(t, Ack{OK: true, Data: "a"}, c.ack)
+	require.Equal(t, []string{"teams", "teams"}, conn.emitted)
+
+}
+
+func TestCheckEventSpec(t *testing.T) {
+	require.NoError(t, checkEventSpec("/", "pickTeam", func(s socketio.Conn, req PickTeamRequest) {}))
+	require.NoError(t, checkEventSpec("/", "getTeams", func(s socketio.Conn) {}))
+	require.Error(t, checkEventSpec("/", "pickTeam", func(s socketio.Conn) {}))
+	require.Error(t, checkEventSpec("/", "pickTeam", func(s socketio.Conn, team string) {}))
+	require.Error(t, checkEventSpec("/", "getTeams", func(s socketio.Conn, req PickTeamRequest) {}))
+	require.Error(t, checkEventSpec("/", "noSuchEvent", func(s socketio.Conn) {}))
+}
+
+func TestProtocolSpecPayloads(t *testing.T) {
+	spec := NewProtocolSpec()
+	for _, event := range append(spec.ClientEvents, spec.ServerEvents...) {
+		if event.Payload == nil {
+			continue
+		}
+		require.Contains(t, event.Payload, "$ref", "payload of %s%s is not a struct", event.Namespace, event.Name)
+	}
+
+	// every payload speaks snake_case, like the entities and the REST API
+	field := regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	for name, def := range spec.Definitions {
+		props, _ := def["properties"].(JSONSchema)
+		for prop := range props {
+			require.Regexp(t, field, prop, "field of %s", name)
+		}
+	}
+}
+
+func TestProtocolSpecIsUpToDate(t *testing.T) {
+	want, err := os.ReadFile("../../docs/socket-protocol.json")
+	require.NoError(t, err)
+
+	var got bytes.Buffer
+	enc := json.NewEncoder(&got)
+	enc.SetIndent("", "  ")
+	require.NoError(t, enc.Encode(NewProtocolSpec()))
+	require.Equal(t, string(want), got.String(), "run make socketspec")
+}
@@ -27,7 +27,8 @@ type createQuestionRequest struct {
 	RawQuestion     string `json:"raw_question" binding:"required"`
 	Meta            string `json:"meta"`
 	LongDescription string `json:"long_description"`
-	Type            string `json:"type" binding:"required" oneof:"multiple-choice paragraph heading"`
+	Type            string `json:"type" binding:"required" oneof:"multiple-choice paragraph heading multi-select scale ranking"`
+	TimeLimit       int32  `json:"time_limit" binding:"min=0"`
 }
 
 func (s *QuestionService) CreateQuestion(ctx *gin.Context) {
@@ -42,6 +43,11 @@ func (s *QuestionService) CreateQuestion(ctx *gin.Context) {
 		return
 	}
 
+	if err := checkScaleRange(req.Type, req.Meta); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse(err))
+		return
+	}
+
 	question, err := s.DB.CreateQuestion(ctx, repositories.CreateQuestionParams{
 		ID:              uuid.NewString(),
 		SlideID:         req.SlideID,
@@ -50,6 +56,7 @@ func (s *QuestionService) CreateQuestion(ctx *gin.Context) {
 		Meta:            req.Meta,
 		LongDescription: req.LongDescription,
 		Type:            req.Type,
+		TimeLimit:       req.TimeLimit,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
@@ -105,7 +112,8 @@ type updateQuestionRequest struct {
 	RawQuestion     string `json:"raw_question" binding:"required"`
 	Meta            string `json:"meta"`
 	LongDescription string `json:"long_description"`
-	Type            string `json:"type" binding:"required" oneof:"multiple-choice paragraph heading"`
+	Type            string `json:"type" binding:"required" oneof:"multiple-choice paragraph heading multi-select scale ranking"`
+	TimeLimit       int32  `json:"time_limit" binding:"min=0"`
 }
 
 func (s *QuestionService) UpdateQuestion(ctx *gin.Context) {
@@ -121,6 +129,11 @@ func (s *QuestionService) UpdateQuestion(ctx *gin.Context) {
 		return
 	}
 
+	if err := checkScaleRange(req.Type, req.Meta); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse(err))
+		return
+	}
+
 	question, err := s.DB.UpdateQuestion(ctx, repositories.UpdateQuestionParams{
 		ID:              req.QuestionID,
 		Index:           req.Index,
@@ -128,6 +141,7 @@ func (s *QuestionService) UpdateQuestion(ctx *gin.Context) {
 		Meta:            req.Meta,
 		LongDescription: req.LongDescription,
 		Type:            req.Type,
+		TimeLimit:       req.TimeLimit,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse(err))
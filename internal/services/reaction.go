@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+// reactionInterval is how often the reactions of a room are sent out.
+const reactionInterval = 500 * time.Millisecond
+
+// liveReactions are the emoji the audience can react with.
+var liveReactions = []string{"👍", "❤️", "😂", "❓"}
+
+// LiveReactions is how many of each emoji a room got since the last batch.
+type LiveReactions struct {
+	QuestionID string         `json:"question_id"`
+	Counts     map[string]int `json:"counts"`
+}
+
+func validateLiveReaction(emoji string) error {
+	for _, reaction := range liveReactions {
+		if reaction == emoji {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown reaction: %s", emoji)
+}
+
+// reactionBatcher adds up the reactions of each room on this instance until
+// they are sent out.
+type reactionBatcher struct {
+	lock sync.Mutex
+	// [Room ID] -> [emoji] -> count
+	pending map[string]map[string]int
+}
+
+func newReactionBatcher() *reactionBatcher {
+	return &reactionBatcher{pending: make(map[string]map[string]int)}
+}
+
+func (b *reactionBatcher) add(roomID, emoji string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.pending[roomID] == nil {
+		b.pending[roomID] = make(map[string]int)
+	}
+	b.pending[roomID][emoji]++
+}
+
+// take returns the reactions added since it was last called.
+func (b *reactionBatcher) take() map[string]map[string]int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	pending := b.pending
+	b.pending = make(map[string]map[string]int)
+	return pending
+}
+
+// runReactionBatcher sends the reactions of every room once per interval and
+// adds them to the totals of the question the room is on. Reactions that come
+// in while no question is open, as in student-paced rooms, are kept with an
+// empty question ID.
+func runReactionBatcher(server *Server, broadcaster Broadcaster, batcher *reactionBatcher) {
+	ticker := time.NewTicker(reactionInterval)
+	defer ticker.Stop()
+
+	rooms := server.PresentationService.Rooms
+	for range ticker.C {
+		for roomID, counts := range batcher.take() {
+			r, ok, err := rooms.Get(context.Background(), roomID)
+			if err != nil || !ok || r.SessionID == "" {
+				continue
+			}
+			questionID := r.Question.QuestionID
+			broadcaster.BroadcastToRoom("/", roomID, "reactions", LiveReactions{
+				QuestionID: questionID,
+				Counts:     counts,
+			})
+			if err := server.SlideService.SaveReactionTotals(r.SessionID, questionID, counts); err != nil {
+				fmt.Println("save reaction totals failed:", err)
+			}
+		}
+	}
+}
+
+// SaveReactionTotals adds a batch of reactions to the totals of the question.
+func (s *SlideService) SaveReactionTotals(sessionID, questionID string, counts map[string]int) error {
+	for emoji, count := range counts {
+		err := s.DB.AddReactionTotal(context.Background(), repositories.AddReactionTotalParams{
+			SessionID:  sessionID,
+			QuestionID: questionID,
+			Emoji:      emoji,
+			Count:      int32(count),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+func TestValidateLiveReaction(t *testing.T) {
+	for _, emoji := range liveReactions {
+		require.NoError(t, validateLiveReaction(emoji))
+	}
+	require.Error(t, validateLiveReaction(""))
+	require.Error(t, validateLiveReaction("🎉"))
+}
+
+func TestReactionBatcher(t *testing.T) {
+	b := newReactionBatcher()
+	b.add("room", "👍")
+	b.add("room", "👍")
+	b.add("room", "❓")
+	b.add("other", "❤️")
+	require.Equal(t, map[string]map[string]int{
+		"room":  {"👍": 2, "❓": 1},
+		"other": {"❤️": 1},
+	}, b.take())
+	require.Empty(t, b.take())
+}
+
+func TestSaveReactionTotals(t *testing.T) {
+	db, config := newTestStore(t)
+	service := NewSlideService(db, &config)
+
+	sessionID := utils.RandomString(12)
+	require.NoError(t, service.SaveReactionTotals(sessionID, "q1", map[string]int{"👍": 2, "❓": 1}))
+	require.NoError(t, service.SaveReactionTotals(sessionID, "q1", map[string]int{"👍": 3}))
+
+	totals, err := db.ListReactionTotalsBySession(context.Background(), sessionID)
+	require.NoError(t, err)
+	res := make([]entities.ReactionTotal, 0, len(totals))
+	for _, total := range totals {
+		res = append(res, total.ReactionTotal)
+	}
+	require.ElementsMatch(t, []entities.ReactionTotal{
+		{SessionID: sessionID, QuestionID: "q1", Emoji: "👍", Count: 5},
+		{SessionID: sessionID, QuestionID: "q1", Emoji: "❓", Count: 1},
+	}, res)
+}
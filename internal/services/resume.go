@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+// resumeChatLimit is how many of the latest chat messages a snapshot carries.
+const resumeChatLimit = 50
+
+type ResumeQuestion struct {
+	Question entities.Question `json:"question"`
+	Answers  []entities.Answer `json:"answers"`
+	Closed   bool              `json:"closed"`
+	Locked   bool              `json:"locked"`
+	// Deadline is zero when the question has no time limit
+	Deadline time.Time `json:"deadline"`
+}
+
+// ResumeSnapshot is everything a client needs to pick up a presentation again
+// after reconnecting.
+type ResumeSnapshot struct {
+	RoomID          string          `json:"room_id"`
+	SessionID       string          `json:"session_id"`
+	State           int             `json:"state"`
+	IsQuiz          bool            `json:"is_quiz"`
+	Question        *ResumeQuestion `json:"question"`
+	MyAnswer        string          `json:"my_answer"`
+	ResultsRevealed bool            `json:"results_revealed"`
+	// StatisticEvent is the event Statistic is sent with in the room:
+	// showStatistic, scaleStatistic or rankingStatistic
+	StatisticEvent string             `json:"statistic_event"`
+	Statistic      interface{}        `json:"statistic"`
+	Leaderboard    *LeaderboardEntry  `json:"leaderboard"`
+	ChatMsgs       []entities.ChatMsg `json:"chat_msgs"`
+	// Pace is set in student-paced rooms, Question is then the one the
+	// participant is on
+	Pace *PaceState `json:"pace"`
+}
+
+// Resume builds the snapshot of the room for a participant from a single read
+// of the room state and the answer history of its session. Hosts get the
+// results before they are revealed.
+func (s *PresentationService) Resume(ctx context.Context, roomID string, viewer Participant) (ResumeSnapshot, error) {
+	r, ok, err := s.Rooms.Get(ctx, roomID)
+	if err != nil {
+		return ResumeSnapshot{}, err
+	}
+	if !ok || r.SessionID == "" {
+		return ResumeSnapshot{}, errNotRunning
+	}
+
+	snapshot := ResumeSnapshot{
+		RoomID:          r.ID,
+		SessionID:       r.SessionID,
+		State:           r.State,
+		IsQuiz:          r.IsQuiz,
+		ResultsRevealed: resultsRevealed(r, r.Question.QuestionID),
+		ChatMsgs:        []entities.ChatMsg{},
+	}
+
+	index := r.State
+	if r.Paced {
+		pace := r.paceState(viewer.key())
+		snapshot.Pace = &pace
+		index = pace.Index
+	}
+
+	question, err := s.DB.GetQuestionBySlideAndIndex(ctx, repositories.GetQuestionBySlideAndIndexParams{
+		SlideID: roomID,
+		Index:   int16(index),
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return ResumeSnapshot{}, err
+	}
+	if err == nil {
+		if snapshot.Question, err = s.resumeQuestion(ctx, r, question.Question, snapshot.ResultsRevealed); err != nil {
+			return ResumeSnapshot{}, err
+		}
+		if snapshot.MyAnswer, err = s.submittedAnswer(ctx, r, viewer, question.ID); err != nil {
+			return ResumeSnapshot{}, err
+		}
+		if snapshot.ResultsRevealed || r.CheckTeacher(viewer.UserID) == nil {
+			snapshot.StatisticEvent, snapshot.Statistic, err = s.Slides.GetQuestionStatistic(r.SessionID, question)
+			if err != nil {
+				return ResumeSnapshot{}, err
+			}
+		}
+	}
+
+	scores, err := s.DB.GetLeaderboardBySessionID(ctx, r.SessionID)
+	if err != nil {
+		return ResumeSnapshot{}, err
+	}
+	for _, entry := range rankLeaderboard(scores) {
+		if entry.Key == viewer.key() {
+			entry := entry
+			snapshot.Leaderboard = &entry
+			break
+		}
+	}
+
+	// the latest page of the chat, without the replies, as GetChatPage sends it
+	chatMsgs, err := s.DB.ListChatPage(ctx, repositories.ListChatPageParams{
+		SessionID:  r.SessionID,
+		BeforeTime: chatCursorEnd,
+		Limit:      resumeChatLimit,
+	})
+	if err != nil {
+		return ResumeSnapshot{}, err
+	}
+	for i := len(chatMsgs) - 1; i >= 0; i-- {
+		snapshot.ChatMsgs = append(snapshot.ChatMsgs, publicChatMsg(chatMsgs[i].ChatMsg))
+	}
+
+	return snapshot, nil
+}
+
+func (s *PresentationService) resumeQuestion(ctx context.Context, r LiveRoom, question entities.Question, revealed bool) (*ResumeQuestion, error) {
+	answers, err := s.DB.GetAnswersByQuestion(ctx, question.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ResumeQuestion{
+		Question: question,
+		Answers:  make([]entities.Answer, 0, len(answers)),
+		Closed:   errors.Is(checkQuestionOpen(r, question.ID), errQuestionClosed),
+		Locked:   r.Question.QuestionID == question.ID && r.Question.Locked,
+	}
+	if _, deadline, ok := answerWindow(r, question.ID); ok {
+		res.Deadline = deadline
+	}
+	for _, answer := range answers {
+		// the correct answer is part of the results
+		if !revealed {
+			answer.IsCorrect = false
+		}
+		res.Answers = append(res.Answers, answer.Answer)
+	}
+	return res, nil
+}
+
+// submittedAnswer returns the answer the participant gave to the question, from
+// the room state or, when it is not there, the answer history.
+func (s *PresentationService) submittedAnswer(ctx context.Context, r LiveRoom, viewer Participant, questionID string) (string, error) {
+	if p := r.participant(viewer.key()); p != nil && p.Answer[questionID] != "" {
+		return p.Answer[questionID], nil
+	}
+
+	history, err := s.DB.GetAnswerHistory(ctx, repositories.GetAnswerHistoryParams{
+		SessionID:   r.SessionID,
+		Participant: viewer.key(),
+		QuestionID:  questionID,
+	})
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return history.AnswerID, nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+// fakeResumeStore serves a slide with one question and the answer history of
+// a single participant.
+type fakeResumeStore struct {
+	repositories.Store
+
+	question   entities.Question
+	answers    []entities.Answer
+	history    []entities.AnswerHistory
+	selections []entities.AnswerSelection
+	chatMsgs   []entities.ChatMsg
+}
+
+func (f *fakeResumeStore) GetQuestionBySlideAndIndex(ctx context.Context, arg repositories.GetQuestionBySlideAndIndexParams) (repositories.Question, error) {
+	if arg.Index != f.question.Index {
+		return repositories.Question{}, sql.ErrNoRows
+	}
+	return repositories.Question{Question: f.question}, nil
+}
+
+func (f *fakeResumeStore) GetAnswersByQuestion(ctx context.Context, questionID string) ([]repositories.Answer, error) {
+	res := make([]repositories.Answer, 0, len(f.answers))
+	for _, answer := range f.answers {
+		res = append(res, repositories.Answer{Answer: answer})
+	}
+	return res, nil
+}
+
+func (f *fakeResumeStore) GetAnswerHistory(ctx context.Context, arg repositories.GetAnswerHistoryParams) (repositories.AnswerHistory, error) {
+	for _, h := range f.history {
+		if h.SessionID == arg.SessionID && h.Participant == arg.Participant && h.QuestionID == arg.QuestionID {
+			return repositories.AnswerHistory{AnswerHistory: h}, nil
+		}
+	}
+	return repositories.AnswerHistory{}, sql.ErrNoRows
+}
+
+func (f *fakeResumeStore) CountAnswerByQuestionID(ctx context.Context, arg repositories.CountAnswerByQuestionIDParams) ([]repositories.CountAnswerByQuestionIDRow, error) {
+	counts := make(map[string]int64)
+	for _, h := range f.history {
+		if h.SessionID == arg.SessionID && h.QuestionID == arg.QuestionID {
+			counts[h.AnswerID]++
+		}
+	}
+	res := make([]repositories.CountAnswerByQuestionIDRow, 0, len(counts))
+	for answerID, count := range counts {
+		res = append(res, repositories.CountAnswerByQuestionIDRow{QuestionID: arg.QuestionID, AnswerID: answerID, Count: count})
+	}
+	return res, nil
+}
+
+func (f *fakeResumeStore) ListAnswerSelectionsByQuestion(ctx context.Context, arg repositories.ListAnswerSelectionsByQuestionParams) ([]repositories.AnswerSelection, error) {
+	res := make([]repositories.AnswerSelection, 0, len(f.selections))
+	for _, selection := range f.selections {
+		if selection.SessionID == arg.SessionID && selection.QuestionID == arg.QuestionID {
+			res = append(res, repositories.AnswerSelection{AnswerSelection: selection})
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeResumeStore) GetLeaderboardBySessionID(ctx context.Context, sessionID string) ([]repositories.GetLeaderboardBySessionIDRow, error) {
+	res := make([]repositories.GetLeaderboardBySessionIDRow, 0, len(f.history))
+	for _, h := range f.history {
+		res = append(res, repositories.GetLeaderboardBySessionIDRow{Participant: h.Participant, Username: h.Username, Score: h.Points})
+	}
+	return res, nil
+}
+
+func (f *fakeResumeStore) ListChatPage(ctx context.Context, arg repositories.ListChatPageParams) ([]repositories.ChatMsg, error) {
+	res := make([]repositories.ChatMsg, 0, arg.Limit)
+	for i := len(f.chatMsgs) - 1; i >= 0 && len(res) < int(arg.Limit); i-- {
+		if f.chatMsgs[i].SessionID == arg.SessionID && f.chatMsgs[i].ParentID == "" {
+			res = append(res, repositories.ChatMsg{ChatMsg: f.chatMsgs[i]})
+		}
+	}
+	return res, nil
+}
+
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	sessionID := utils.RandomString(12)
+	question := entities.Question{ID: utils.RandomString(12), SlideID: roomID, Index: 1}
+	store := &fakeResumeStore{
+		question: question,
+		answers: []entities.Answer{
+			{ID: "right", QuestionID: question.ID, IsCorrect: true},
+			{ID: "wrong", QuestionID: question.ID},
+		},
+		history: []entities.AnswerHistory{
+			{SessionID: sessionID, Participant: "name/student", Username: "student", QuestionID: question.ID, AnswerID: "right", Points: 1000},
+		},
+	}
+	for i := 0; i < resumeChatLimit+10; i++ {
+		store.chatMsgs = append(store.chatMsgs, entities.ChatMsg{ID: utils.RandomString(12), SessionID: sessionID})
+	}
+	// replies stay in their thread
+	latest := store.chatMsgs[len(store.chatMsgs)-1]
+	store.chatMsgs = append(store.chatMsgs, entities.ChatMsg{ID: utils.RandomString(12), SessionID: sessionID, ParentID: latest.ID})
+
+	rooms := NewRoomManager()
+	config := &utils.Config{}
+	svc := NewPresentationService(store, rooms, NewSlideService(store, config), config)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", UserID: utils.RandomString(12), SID: "teacher-sid"}, false, "")
+	require.NoError(t, err)
+	// the answer is only in the history, as after the room moved to another instance
+	require.NoError(t, rooms.Join(ctx, roomID, Participant{Username: "student", SID: "student-sid"}))
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.SessionID = sessionID
+		r.IsQuiz = true
+		r.Question = QuestionWindow{QuestionID: question.ID}
+		return nil
+	})
+	require.NoError(t, err)
+
+	snapshot, err := svc.Resume(ctx, roomID, Participant{Username: "student"})
+	require.NoError(t, err)
+	require.Equal(t, sessionID, snapshot.SessionID)
+	require.Equal(t, 1, snapshot.State)
+	require.NotNil(t, snapshot.Question)
+	require.Equal(t, question.ID, snapshot.Question.Question.ID)
+	require.False(t, snapshot.Question.Closed)
+	require.Equal(t, "right", snapshot.MyAnswer)
+	require.False(t, snapshot.ResultsRevealed)
+	require.Nil(t, snapshot.Statistic)
+	for _, answer := range snapshot.Question.Answers {
+		require.False(t, answer.IsCorrect)
+	}
+	require.Equal(t, &LeaderboardEntry{Rank: 1, Key: "name/student", Username: "student", Score: 1000}, snapshot.Leaderboard)
+	require.Len(t, snapshot.ChatMsgs, resumeChatLimit)
+	require.Equal(t, latest, snapshot.ChatMsgs[resumeChatLimit-1])
+
+	_, _, err = closeCurrentQuestion(ctx, rooms, roomID)
+	require.NoError(t, err)
+	snapshot, err = svc.Resume(ctx, roomID, Participant{Username: "student"})
+	require.NoError(t, err)
+	require.True(t, snapshot.Question.Closed)
+	require.True(t, snapshot.ResultsRevealed)
+	require.Equal(t, "showStatistic", snapshot.StatisticEvent)
+	require.Equal(t, AnswerStatistic{QuestionID: question.ID, Counts: []AnswerCount{{AnswerID: "right", Count: 1}}}, snapshot.Statistic)
+	require.True(t, snapshot.Question.Answers[0].IsCorrect)
+
+	_, err = svc.Resume(ctx, utils.RandomString(12), Participant{Username: "student"})
+	require.Error(t, err)
+}
+
+func TestResumeSelectionStatistic(t *testing.T) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	sessionID := utils.RandomString(12)
+	question := entities.Question{ID: utils.RandomString(12), SlideID: roomID, Index: 1, Type: constants.QuestionType_MULTI_SELECT}
+	store := &fakeResumeStore{
+		question: question,
+		answers: []entities.Answer{
+			{ID: "a", QuestionID: question.ID},
+			{ID: "b", QuestionID: question.ID},
+		},
+		// the history keeps one answer of each participant, not its selections
+		history: []entities.AnswerHistory{
+			{SessionID: sessionID, Username: "s1", QuestionID: question.ID, AnswerID: "a,b"},
+			{SessionID: sessionID, Username: "s2", QuestionID: question.ID, AnswerID: "a"},
+		},
+		selections: []entities.AnswerSelection{
+			{SessionID: sessionID, Username: "s1", QuestionID: question.ID, AnswerID: "a"},
+			{SessionID: sessionID, Username: "s1", QuestionID: question.ID, AnswerID: "b"},
+			{SessionID: sessionID, Username: "s2", QuestionID: question.ID, AnswerID: "a"},
+		},
+	}
+
+	rooms := NewRoomManager()
+	config := &utils.Config{}
+	svc := NewPresentationService(store, rooms, NewSlideService(store, config), config)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", UserID: utils.RandomString(12), SID: "teacher-sid"}, false, "")
+	require.NoError(t, err)
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.SessionID = sessionID
+		r.IsQuiz = true
+		r.Question = QuestionWindow{QuestionID: question.ID, Revealed: true}
+		return nil
+	})
+	require.NoError(t, err)
+
+	snapshot, err := svc.Resume(ctx, roomID, Participant{Username: "s2"})
+	require.NoError(t, err)
+	require.Equal(t, "showStatistic", snapshot.StatisticEvent)
+	require.Equal(t, AnswerStatistic{
+		QuestionID: question.ID,
+		Counts:     []AnswerCount{{AnswerID: "a", Count: 2}, {AnswerID: "b", Count: 1}},
+	}, snapshot.Statistic)
+}
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+var (
+	ErrRoomNotFound = errors.New("room does not exist")
+	errNotInRoom    = errors.New("you are not in the room")
+	errNotTeacher   = errors.New("you are not a teacher in the room")
+	errNotRunning   = errors.New("presentation is not running")
+	errAnswered     = errors.New("you have already answered this question")
+)
+
+type Participant struct {
+	Username string
+	// UserID is set for participants who are logged in, the host always is
+	UserID string
+	// ID identifies a guest, it is signed into the token the guest rejoins with
+	ID        string
+	Team      string
+	IsTeacher bool
+	Status    string
+	SID       string
+	// [Question ID] -> Answer ID, the text for paragraph questions
+	Answer map[string]string
+	// Pace is the question the participant is on in a student-paced room
+	Pace Pace
+}
+
+// QuestionWindow is the answering window of the question a room is on.
+// Deadline is zero when the question has no time limit. The host can lock
+// answering and reveal the results, which are hidden from the audience at first.
+type QuestionWindow struct {
+	QuestionID string
+	OpenedAt   time.Time
+	Deadline   time.Time
+	Locked     bool
+	Revealed   bool
+}
+
+// LiveRoom is the state of a running presentation, the room ID is the slide ID.
+type LiveRoom struct {
+	ID           string
+	State        int
+	IsGroup      bool
+	GroupID      string
+	IsQuiz       bool
+	Pin          string
+	SessionID    string
+	Participants []Participant
+	Question     QuestionWindow
+	// [Question ID] -> closed
+	ClosedQuestions map[string]bool
+	// [Question ID] -> when answering ends, for the timed questions opened so far
+	Deadlines map[string]time.Time
+	// [Participant key] -> banned for the rest of the session
+	Banned map[string]bool
+	// Lobby keeps new participants pending until the host admits them
+	Lobby bool
+	// MaxParticipants caps the audience, hosts not included. Zero is no cap
+	MaxParticipants int
+	// TeamMode is how participants get into Teams, empty when teams are off
+	TeamMode string
+	Teams    []string
+	// Paced rooms let every participant move through PacedQuestions at its own
+	// pace instead of following State
+	Paced          bool
+	PacedQuestions []PacedQuestion
+	PaceEnded      bool
+	// StemWords counts the forms of a word as one in word clouds
+	StemWords bool
+	// ModerateQuestions keeps questions of the audience pending until a host
+	// approves them
+	ModerateQuestions bool
+	// PinnedQuestion is the question of the audience on the presenter screen
+	PinnedQuestion string
+	// [Participant key] -> muted in chat
+	Muted map[string]bool
+	// SlowMode is how many seconds participants wait between chat messages,
+	// zero when it is off
+	SlowMode int
+	// [Participant key] -> when it last sent a chat message in slow mode
+	LastChat map[string]time.Time
+}
+
+// RoomManager is the in-memory SessionStore. Socket handlers run on many
+// goroutines, so all reads and writes go through its lock. It only works when
+// a single instance is running.
+type RoomManager struct {
+	mu    sync.Mutex
+	rooms map[string]*LiveRoom
+	// [Group ID] -> Room ID
+	groupSlidePresent map[string]string
+	// [PIN] -> Room ID
+	pins map[string]string
+}
+
+var _ SessionStore = (*RoomManager)(nil)
+
+func NewRoomManager() *RoomManager {
+	return &RoomManager{
+		rooms:             make(map[string]*LiveRoom),
+		groupSlidePresent: make(map[string]string),
+		pins:              make(map[string]string),
+	}
+}
+
+func (m *RoomManager) Host(ctx context.Context, roomID string, host Participant, isGroup bool, groupID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.rooms[roomID]
+	if !ok {
+		r = &LiveRoom{ID: roomID, State: 1}
+		m.rooms[roomID] = r
+	}
+	r.addHost(host, isGroup, groupID)
+	if isGroup {
+		m.groupSlidePresent[groupID] = roomID
+	}
+	return !ok, nil
+}
+
+func (m *RoomManager) Join(ctx context.Context, roomID string, participant Participant) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.rooms[roomID]
+	if !ok {
+		return ErrRoomNotFound
+	}
+	updated := r.clone()
+	if err := updated.addParticipant(participant); err != nil {
+		return err
+	}
+	m.rooms[roomID] = &updated
+	return nil
+}
+
+func (m *RoomManager) Disconnect(ctx context.Context, sid string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	closed := make([]string, 0)
+	for id, r := range m.rooms {
+		if r.leave(sid) {
+			m.remove(id)
+			closed = append(closed, id)
+		}
+	}
+	return closed, nil
+}
+
+func (m *RoomManager) Get(ctx context.Context, roomID string) (LiveRoom, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.rooms[roomID]
+	if !ok {
+		return LiveRoom{}, false, nil
+	}
+	return r.clone(), true, nil
+}
+
+func (m *RoomManager) Update(ctx context.Context, roomID string, fn func(r *LiveRoom) error) (LiveRoom, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.rooms[roomID]
+	if !ok {
+		return LiveRoom{}, ErrRoomNotFound
+	}
+	updated := r.clone()
+	if err := fn(&updated); err != nil {
+		return LiveRoom{}, err
+	}
+	*r = updated
+	return r.clone(), nil
+}
+
+func (m *RoomManager) Remove(ctx context.Context, roomID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(roomID)
+	return nil
+}
+
+// remove must be called with m.mu held.
+func (m *RoomManager) remove(roomID string) {
+	r, ok := m.rooms[roomID]
+	if !ok {
+		return
+	}
+	if r.IsGroup && m.groupSlidePresent[r.GroupID] == roomID {
+		delete(m.groupSlidePresent, r.GroupID)
+	}
+	if r.Pin != "" {
+		delete(m.pins, r.Pin)
+	}
+	delete(m.rooms, roomID)
+}
+
+func (m *RoomManager) ActiveRoomIDs(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0)
+	for id, r := range m.rooms {
+		if len(r.Participants) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *RoomManager) GroupPresentation(ctx context.Context, groupID string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	roomID, ok := m.groupSlidePresent[groupID]
+	return roomID, ok, nil
+}
+
+func (m *RoomManager) AssignPin(ctx context.Context, roomID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.rooms[roomID]
+	if !ok {
+		return "", ErrRoomNotFound
+	}
+	if r.Pin != "" {
+		return r.Pin, nil
+	}
+
+	for length := minPinLength; length <= maxPinLength; length++ {
+		for i := 0; i < pinAttempts; i++ {
+			pin := randomPin(length)
+			if _, taken := m.pins[pin]; taken {
+				continue
+			}
+			m.pins[pin] = roomID
+			r.Pin = pin
+			return pin, nil
+		}
+	}
+	return "", ErrNoPinAvailable
+}
+
+func (m *RoomManager) ResolvePin(ctx context.Context, pin string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	roomID, ok := m.pins[pin]
+	return roomID, ok, nil
+}
+
+// addHost adds the host to the room or re-activates it on a new connection.
+func (r *LiveRoom) addHost(host Participant, isGroup bool, groupID string) {
+	r.IsGroup = isGroup
+	if isGroup {
+		r.GroupID = groupID
+	}
+
+	host.IsTeacher = true
+	host.Status = constants.SocketParticipantStatus_ACTIVE
+	if p := r.member(host); p != nil {
+		p.Username = host.Username
+		p.IsTeacher = true
+		p.Status = host.Status
+		p.SID = host.SID
+		return
+	}
+	r.Participants = append(r.Participants, host)
+}
+
+// addParticipant adds a participant to the room or re-activates one that has
+// left. New participants wait in the lobby when it is on.
+func (r *LiveRoom) addParticipant(participant Participant) error {
+	if r.Banned[participant.key()] {
+		return errBanned
+	}
+	p := r.member(participant)
+	if p != nil && p.Status == constants.SocketParticipantStatus_ACTIVE {
+		// joining again from the same connection, as after being admitted
+		if p.SID == participant.SID {
+			return nil
+		}
+		return fmt.Errorf("You are already in the room")
+	}
+	if p != nil && p.Status == constants.SocketParticipantStatus_PENDING {
+		p.SID = participant.SID
+		return nil
+	}
+	if r.full() {
+		return errRoomFull
+	}
+	if p != nil {
+		p.Status = constants.SocketParticipantStatus_ACTIVE
+		p.SID = participant.SID
+		return nil
+	}
+	if participant.UserID == "" && r.nameTaken(participant.Username) {
+		return fmt.Errorf("this name is already taken in the room")
+	}
+
+	if err := r.assignTeam(&participant, participant.Team); err != nil {
+		return err
+	}
+	participant.IsTeacher = false
+	r.startPace(&participant, time.Now())
+	participant.Status = constants.SocketParticipantStatus_ACTIVE
+	if r.Lobby {
+		participant.Status = constants.SocketParticipantStatus_PENDING
+	}
+	r.Participants = append(r.Participants, participant)
+	return nil
+}
+
+// leave marks the participants of a connection as left and reports whether
+// nobody is active in the room anymore. Participants who were never admitted
+// from the lobby are removed.
+func (r *LiveRoom) leave(sid string) bool {
+	allLeft := true
+	participants := r.Participants[:0]
+	for _, p := range r.Participants {
+		if p.SID == sid {
+			if p.Status == constants.SocketParticipantStatus_PENDING {
+				continue
+			}
+			p.Status = constants.SocketParticipantStatus_LEFT
+		}
+		if p.Status == constants.SocketParticipantStatus_ACTIVE {
+			allLeft = false
+		}
+		participants = append(participants, p)
+	}
+	r.Participants = participants
+	return allLeft
+}
+
+func (r *LiveRoom) hasSID(sid string) bool {
+	for _, p := range r.Participants {
+		if p.SID == sid {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LiveRoom) ActiveParticipants() []Participant {
+	activeParticipants := make([]Participant, 0)
+	for _, participant := range r.Participants {
+		if participant.Status == constants.SocketParticipantStatus_ACTIVE {
+			activeParticipants = append(activeParticipants, participant)
+		}
+	}
+	return activeParticipants
+}
+
+// ParticipantInfo is a participant as hosts and the room see it, hosts kick,
+// admit and mute participants by Key.
+type ParticipantInfo struct {
+	Key       string `json:"key"`
+	Username  string `json:"username"`
+	Team      string `json:"team"`
+	IsTeacher bool   `json:"is_teacher"`
+	Status    string `json:"status"`
+}
+
+type ParticipantList struct {
+	Participants []ParticipantInfo `json:"participants"`
+}
+
+func (p Participant) info() ParticipantInfo {
+	return ParticipantInfo{
+		Key:       p.key(),
+		Username:  p.Username,
+		Team:      p.Team,
+		IsTeacher: p.IsTeacher,
+		Status:    p.Status,
+	}
+}
+
+func participantList(participants []Participant) ParticipantList {
+	list := ParticipantList{Participants: make([]ParticipantInfo, 0, len(participants))}
+	for _, p := range participants {
+		list.Participants = append(list.Participants, p.info())
+	}
+	return list
+}
+
+// CheckTeacher checks that the logged in user hosts the room.
+func (r *LiveRoom) CheckTeacher(userID string) error {
+	var participant *Participant
+	if userID != "" {
+		participant = r.member(Participant{UserID: userID})
+	}
+	if participant == nil {
+		return errNotInRoom
+	}
+	if !participant.IsTeacher {
+		return errNotTeacher
+	}
+	return nil
+}
+
+// submitAnswer records the answer of a participant to a question. Quiz rooms
+// score one answer per question, so the first one cannot be changed.
+func (r *LiveRoom) submitAnswer(key, questionID, answerID string) error {
+	participant := r.participant(key)
+	if participant == nil {
+		return errNotInRoom
+	}
+	if r.IsQuiz && participant.Answer[questionID] != "" {
+		return errAnswered
+	}
+	if participant.Answer == nil {
+		participant.Answer = make(map[string]string)
+	}
+	participant.Answer[questionID] = answerID
+	return nil
+}
+
+// participant finds the participant with the key.
+func (r *LiveRoom) participant(key string) *Participant {
+	for i := range r.Participants {
+		if r.Participants[i].key() == key {
+			return &r.Participants[i]
+		}
+	}
+	return nil
+}
+
+// member finds the participant with the same identity, the user for logged in
+// participants and the guest ID for guests.
+func (r *LiveRoom) member(p Participant) *Participant {
+	for i := range r.Participants {
+		if r.Participants[i].UserID != p.UserID || r.Participants[i].ID != p.ID {
+			continue
+		}
+		if p.UserID != "" || p.ID != "" || r.Participants[i].Username == p.Username {
+			return &r.Participants[i]
+		}
+	}
+	return nil
+}
+
+// nameTaken reports whether a guest already goes by the nickname, ignoring
+// case. Logged in participants are told apart by their user and can share
+// their names with anyone.
+func (r *LiveRoom) nameTaken(username string) bool {
+	for _, p := range r.Participants {
+		if p.UserID == "" && strings.EqualFold(p.Username, username) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LiveRoom) clone() LiveRoom {
+	c := *r
+	c.Participants = make([]Participant, len(r.Participants))
+	for i, p := range r.Participants {
+		if p.Answer != nil {
+			answer := make(map[string]string, len(p.Answer))
+			for k, v := range p.Answer {
+				answer[k] = v
+			}
+			p.Answer = answer
+		}
+		c.Participants[i] = p
+	}
+	if r.ClosedQuestions != nil {
+		c.ClosedQuestions = make(map[string]bool, len(r.ClosedQuestions))
+		for k, v := range r.ClosedQuestions {
+			c.ClosedQuestions[k] = v
+		}
+	}
+	if r.Deadlines != nil {
+		c.Deadlines = make(map[string]time.Time, len(r.Deadlines))
+		for k, v := range r.Deadlines {
+			c.Deadlines[k] = v
+		}
+	}
+	if r.Teams != nil {
+		c.Teams = append([]string(nil), r.Teams...)
+	}
+	if r.PacedQuestions != nil {
+		c.PacedQuestions = append([]PacedQuestion(nil), r.PacedQuestions...)
+	}
+	if r.Banned != nil {
+		c.Banned = make(map[string]bool, len(r.Banned))
+		for k, v := range r.Banned {
+			c.Banned[k] = v
+		}
+	}
+	if r.Muted != nil {
+		c.Muted = make(map[string]bool, len(r.Muted))
+		for k, v := range r.Muted {
+			c.Muted[k] = v
+		}
+	}
+	if r.LastChat != nil {
+		c.LastChat = make(map[string]time.Time, len(r.LastChat))
+		for k, v := range r.LastChat {
+			c.LastChat[k] = v
+		}
+	}
+	return c
+}
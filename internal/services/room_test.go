@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+func TestRoomManagerConcurrentJoinSubmitDisconnect(t *testing.T) {
+	testConcurrentJoinSubmitDisconnect(t, NewRoomManager())
+}
+
+func TestRoomManagerClosesRoomWhenAllLeft(t *testing.T) {
+	testClosesRoomWhenAllLeft(t, NewRoomManager())
+}
+
+func TestRoomManagerUpdateIsAtomic(t *testing.T) {
+	testUpdateIsAtomic(t, NewRoomManager())
+}
+
+func TestRoomManagerGuestRejoin(t *testing.T) {
+	testGuestRejoin(t, NewRoomManager())
+}
+
+func TestRoomManagerKickAndBan(t *testing.T) {
+	testKickAndBan(t, NewRoomManager())
+}
+
+func TestRoomManagerLobbyAndCap(t *testing.T) {
+	testLobbyAndCap(t, NewRoomManager())
+}
+
+func testConcurrentJoinSubmitDisconnect(t *testing.T, rooms SessionStore) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", SID: "teacher-sid-" + roomID}, false, "")
+	require.NoError(t, err)
+
+	n := 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			username := fmt.Sprintf("student-%d", i)
+			sid := fmt.Sprintf("sid-%d-%s", i, roomID)
+
+			assert.NoError(t, rooms.Join(ctx, roomID, Participant{Username: username, SID: sid}))
+			assert.NoError(t, submitAnswer(ctx, rooms, roomID, "name/"+username, "question", fmt.Sprint(i)))
+			_, err := activeParticipants(ctx, rooms, roomID)
+			assert.NoError(t, err)
+			_, err = rooms.ActiveRoomIDs(ctx)
+			assert.NoError(t, err)
+			if i%2 == 0 {
+				_, err := rooms.Disconnect(ctx, sid)
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	r, ok, err := rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, r.Participants, n+1)
+	for _, p := range r.Participants {
+		if p.IsTeacher {
+			continue
+		}
+		require.Len(t, p.Answer, 1)
+	}
+	require.Len(t, r.ActiveParticipants(), n/2+1)
+	require.NoError(t, rooms.Remove(ctx, roomID))
+}
+
+func testClosesRoomWhenAllLeft(t *testing.T, rooms SessionStore) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	groupID := utils.RandomString(12)
+	teacherSID := "teacher-sid-" + roomID
+	studentSID := "student-sid-" + roomID
+
+	teacherID := utils.RandomString(12)
+	require.ErrorIs(t, rooms.Join(ctx, roomID, Participant{Username: "student", SID: studentSID}), ErrRoomNotFound)
+	created, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", UserID: teacherID, SID: teacherSID}, true, groupID)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NoError(t, rooms.Join(ctx, roomID, Participant{Username: "student", SID: studentSID}))
+	require.Error(t, rooms.Join(ctx, roomID, Participant{Username: "student", SID: "other-sid"}))
+	// logged in participants are told apart by their user, not their name
+	guestSID := "guest-sid-" + roomID
+	userSID := "user-sid-" + roomID
+	require.NoError(t, rooms.Join(ctx, roomID, Participant{Username: "teacher", ID: utils.RandomString(12), SID: guestSID}))
+	require.NoError(t, rooms.Join(ctx, roomID, Participant{Username: "student", UserID: utils.RandomString(12), SID: userSID}))
+
+	presenting, ok, err := rooms.GroupPresentation(ctx, groupID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, roomID, presenting)
+
+	pin, err := rooms.AssignPin(ctx, roomID)
+	require.NoError(t, err)
+	require.True(t, isRoomPin(pin))
+	resolved, ok, err := rooms.ResolvePin(ctx, pin)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, roomID, resolved)
+
+	require.NoError(t, checkTeacher(ctx, rooms, roomID, teacherID))
+	require.Error(t, checkTeacher(ctx, rooms, roomID, ""))
+	require.Error(t, checkTeacher(ctx, rooms, roomID, utils.RandomString(12)))
+
+	for _, sid := range []string{teacherSID, guestSID, userSID} {
+		closed, err := rooms.Disconnect(ctx, sid)
+		require.NoError(t, err)
+		require.Empty(t, closed)
+	}
+	closed, err := rooms.Disconnect(ctx, studentSID)
+	require.NoError(t, err)
+	require.Equal(t, []string{roomID}, closed)
+
+	_, ok, err = rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = rooms.ResolvePin(ctx, pin)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = rooms.GroupPresentation(ctx, groupID)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func testUpdateIsAtomic(t *testing.T, rooms SessionStore) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", SID: "teacher-sid-" + roomID}, false, "")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+				r.State++
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	r, ok, err := rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 101, r.State)
+	require.Equal(t, constants.SocketParticipantStatus_ACTIVE, r.Participants[0].Status)
+
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.State = 0
+		return fmt.Errorf("rejected")
+	})
+	require.Error(t, err)
+	r, _, err = rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.Equal(t, 101, r.State)
+
+	_, err = rooms.Update(ctx, utils.RandomString(12), func(r *LiveRoom) error {
+		return nil
+	})
+	require.ErrorIs(t, err, ErrRoomNotFound)
+	require.NoError(t, rooms.Remove(ctx, roomID))
+}
+
+func testGuestRejoin(t *testing.T, rooms SessionStore) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", UserID: utils.RandomString(12), SID: "teacher-sid-" + roomID}, false, "")
+	require.NoError(t, err)
+
+	guest := Participant{ID: utils.RandomString(12), Username: "Guest", SID: "guest-sid-" + roomID}
+	require.NoError(t, rooms.Join(ctx, roomID, guest))
+	require.NoError(t, submitAnswer(ctx, rooms, roomID, guest.key(), "question", "answer"))
+	_, err = rooms.Disconnect(ctx, guest.SID)
+	require.NoError(t, err)
+
+	// nicknames are unique in the room, also after the guest has left
+	require.Error(t, rooms.Join(ctx, roomID, Participant{ID: utils.RandomString(12), Username: "guest", SID: "other-sid-" + roomID}))
+
+	guest.SID = "new-guest-sid-" + roomID
+	require.NoError(t, rooms.Join(ctx, roomID, guest))
+
+	r, ok, err := rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, r.Participants, 2)
+	p := r.participant(guest.key())
+	require.NotNil(t, p)
+	require.Equal(t, guest.SID, p.SID)
+	require.Equal(t, constants.SocketParticipantStatus_ACTIVE, p.Status)
+	require.Equal(t, map[string]string{"question": "answer"}, p.Answer)
+	require.NoError(t, rooms.Remove(ctx, roomID))
+}
+
+func testKickAndBan(t *testing.T, rooms SessionStore) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	teacher := Participant{Username: "teacher", UserID: utils.RandomString(12), SID: "teacher-sid-" + roomID}
+	_, err := rooms.Host(ctx, roomID, teacher, false, "")
+	require.NoError(t, err)
+
+	kicked := Participant{ID: utils.RandomString(12), Username: "kicked", SID: "kicked-sid-" + roomID}
+	banned := Participant{UserID: utils.RandomString(12), Username: "banned", SID: "banned-sid-" + roomID}
+	require.NoError(t, rooms.Join(ctx, roomID, kicked))
+	require.NoError(t, rooms.Join(ctx, roomID, banned))
+
+	kick := func(key string, ban bool) (Participant, error) {
+		var p Participant
+		_, err := rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+			var err error
+			p, err = r.kick(key, ban)
+			return err
+		})
+		return p, err
+	}
+
+	_, err = kick(teacher.key(), false)
+	require.Error(t, err)
+	_, err = kick("name/kicked", false)
+	require.Error(t, err)
+
+	p, err := kick(kicked.key(), false)
+	require.NoError(t, err)
+	require.Equal(t, kicked.SID, p.SID)
+	p, err = kick(banned.key(), true)
+	require.NoError(t, err)
+	require.Equal(t, banned.SID, p.SID)
+
+	participants, err := activeParticipants(ctx, rooms, roomID)
+	require.NoError(t, err)
+	require.Len(t, participants, 1)
+
+	// a kick without a ban only removes the participant for now
+	kicked.SID = "new-kicked-sid-" + roomID
+	require.NoError(t, rooms.Join(ctx, roomID, kicked))
+	banned.SID = "new-banned-sid-" + roomID
+	require.ErrorIs(t, rooms.Join(ctx, roomID, banned), errBanned)
+	require.NoError(t, rooms.Remove(ctx, roomID))
+}
+
+func testLobbyAndCap(t *testing.T, rooms SessionStore) {
+	ctx := context.Background()
+	roomID := utils.RandomString(12)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", UserID: utils.RandomString(12), SID: "teacher-sid-" + roomID}, false, "")
+	require.NoError(t, err)
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.Lobby = true
+		r.MaxParticipants = 2
+		return nil
+	})
+	require.NoError(t, err)
+
+	first := Participant{ID: utils.RandomString(12), Username: "first", SID: "first-sid-" + roomID}
+	second := Participant{ID: utils.RandomString(12), Username: "second", SID: "second-sid-" + roomID}
+	third := Participant{ID: utils.RandomString(12), Username: "third", SID: "third-sid-" + roomID}
+	require.NoError(t, rooms.Join(ctx, roomID, first))
+	require.NoError(t, rooms.Join(ctx, roomID, second))
+	// waiting participants count towards the cap
+	require.ErrorIs(t, rooms.Join(ctx, roomID, third), errRoomFull)
+
+	r, _, err := rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.Len(t, r.PendingParticipants(), 2)
+	require.Len(t, r.ActiveParticipants(), 1)
+
+	var admitted []Participant
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		admitted, err = r.admit(first.key())
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, admitted, 1)
+	require.Equal(t, first.SID, admitted[0].SID)
+	// the admitted participant joins again from the same connection
+	require.NoError(t, rooms.Join(ctx, roomID, first))
+
+	// leaving the lobby gives the place back
+	_, err = rooms.Disconnect(ctx, second.SID)
+	require.NoError(t, err)
+	r, _, err = rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.Empty(t, r.PendingParticipants())
+	require.Len(t, r.Participants, 2)
+
+	require.NoError(t, rooms.Join(ctx, roomID, third))
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		admitted, err = r.admit("")
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, admitted, 1)
+	require.Equal(t, "third", admitted[0].Username)
+
+	r, _, err = rooms.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.Len(t, r.ActiveParticipants(), 3)
+	require.NoError(t, rooms.Remove(ctx, roomID))
+}
+
+func TestQuizKeepsFirstAnswer(t *testing.T) {
+	r := LiveRoom{Participants: []Participant{{Username: "a"}}}
+	require.NoError(t, r.submitAnswer("name/a", "question", "first"))
+	require.NoError(t, r.submitAnswer("name/a", "question", "second"))
+	require.Equal(t, "second", r.Participants[0].Answer["question"])
+
+	r.IsQuiz = true
+	require.ErrorIs(t, r.submitAnswer("name/a", "question", "third"), errAnswered)
+	require.NoError(t, r.submitAnswer("name/a", "other", "first"))
+	require.Equal(t, "second", r.Participants[0].Answer["question"])
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+const (
+	maxQuestionPoints = 1000
+	podiumSize        = 3
+)
+
+// LeaderboardEntry is the score of a participant, participants who share a
+// username are told apart by Key.
+type LeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	Key      string `json:"key"`
+	Username string `json:"username"`
+	Score    int    `json:"score"`
+}
+
+// Leaderboard is sent as leaderboard and, with the top entries, as podium.
+type Leaderboard struct {
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+// calculatePoints awards a correct answer between half and all of the maximum
+// points depending on how much of the time limit was used. Questions without a
+// time limit always give the maximum.
+func calculatePoints(correct bool, elapsed, limit time.Duration) int {
+	if !correct {
+		return 0
+	}
+	if limit <= 0 {
+		return maxQuestionPoints
+	}
+
+	ratio := float64(elapsed) / float64(limit)
+	ratio = math.Max(0, math.Min(1, ratio))
+	return int(math.Round(maxQuestionPoints * (1 - ratio/2)))
+}
+
+// CheckAnswer loads the answer to check that it belongs to the question and
+// reports whether it is correct.
+func (s *SlideService) CheckAnswer(questionID, answerID string) (bool, error) {
+	answer, err := s.DB.GetAnswer(context.Background(), answerID)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("answer does not exist")
+	}
+	if err != nil {
+		return false, err
+	}
+	if answer.QuestionID != questionID {
+		return false, fmt.Errorf("answer does not belong to the question")
+	}
+
+	return answer.IsCorrect, nil
+}
+
+// scoreAnswer returns the points of a correct or wrong answer submitted at
+// answeredAt for a question opened at openedAt, without touching the store. A
+// zero deadline means no time limit.
+func scoreAnswer(correct bool, openedAt, deadline, answeredAt time.Time) int {
+	var limit time.Duration
+	if !deadline.IsZero() {
+		limit = deadline.Sub(openedAt)
+	}
+	return calculatePoints(correct, answeredAt.Sub(openedAt), limit)
+}
+
+func (s *SlideService) GetLeaderboard(sessionID string) ([]LeaderboardEntry, error) {
+	res, err := s.DB.GetLeaderboardBySessionID(context.Background(), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return rankLeaderboard(res), nil
+}
+
+func rankLeaderboard(res []repositories.GetLeaderboardBySessionIDRow) []LeaderboardEntry {
+	leaderboard := make([]LeaderboardEntry, 0, len(res))
+	for i, row := range res {
+		rank := i + 1
+		// participants with the same score share a rank
+		if i > 0 && int(row.Score) == leaderboard[i-1].Score {
+			rank = leaderboard[i-1].Rank
+		}
+		leaderboard = append(leaderboard, LeaderboardEntry{
+			Rank:     rank,
+			Key:      row.Participant,
+			Username: row.Username,
+			Score:    int(row.Score),
+		})
+	}
+	return leaderboard
+}
+
+func (s *SlideService) GetPodium(sessionID string) ([]LeaderboardEntry, error) {
+	leaderboard, err := s.GetLeaderboard(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	podium := make([]LeaderboardEntry, 0, podiumSize)
+	for _, entry := range leaderboard {
+		if entry.Rank > podiumSize {
+			break
+		}
+		podium = append(podium, entry)
+	}
+	return podium, nil
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculatePoints(t *testing.T) {
+	limit := 20 * time.Second
+
+	require.Equal(t, 0, calculatePoints(false, time.Second, limit))
+	require.Equal(t, maxQuestionPoints, calculatePoints(true, 0, limit))
+	require.Equal(t, 750, calculatePoints(true, 10*time.Second, limit))
+	require.Equal(t, 500, calculatePoints(true, limit, limit))
+	require.Equal(t, 500, calculatePoints(true, 2*limit, limit))
+	require.Equal(t, maxQuestionPoints, calculatePoints(true, time.Hour, 0))
+
+	fast := calculatePoints(true, 2*time.Second, limit)
+	slow := calculatePoints(true, 15*time.Second, limit)
+	require.Greater(t, fast, slow)
+}
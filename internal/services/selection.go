@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+// scaleRanges are the scales a scale question can use, picked with its meta.
+var scaleRanges = map[string][2]int{
+	constants.ScaleRange_FIVE: {1, 5},
+	constants.ScaleRange_TEN:  {0, 10},
+}
+
+// SelectedAnswer is a checked answer to a multi-select, scale or ranking
+// question. AnswerID is what the answer history keeps of it.
+type SelectedAnswer struct {
+	Selections []repositories.CreateAnswerSelectionParams
+	AnswerID   string
+	Correct    bool
+}
+
+type ScaleCount struct {
+	Value int `json:"value"`
+	Count int `json:"count"`
+}
+
+// ScaleStatistic sums up the answers to a scale question, Histogram has every
+// value of the scale.
+type ScaleStatistic struct {
+	QuestionID string       `json:"question_id"`
+	Min        int          `json:"min"`
+	Max        int          `json:"max"`
+	Responses  int          `json:"responses"`
+	Mean       float64      `json:"mean"`
+	Median     float64      `json:"median"`
+	Histogram  []ScaleCount `json:"histogram"`
+}
+
+// RankingResult is where the participants ranked an answer on average, 1 is first.
+type RankingResult struct {
+	AnswerID        string  `json:"answer_id"`
+	AveragePosition float64 `json:"average_position"`
+	Responses       int     `json:"responses"`
+}
+
+// RankingStatistic is the results of a ranking question, best ranked first.
+type RankingStatistic struct {
+	QuestionID string          `json:"question_id"`
+	Results    []RankingResult `json:"results"`
+}
+
+// isSelectionType reports whether answers to the question type are stored as
+// selections instead of a single answer.
+func isSelectionType(questionType string) bool {
+	switch questionType {
+	case constants.QuestionType_MULTI_SELECT, constants.QuestionType_SCALE, constants.QuestionType_RANKING:
+		return true
+	}
+	return false
+}
+
+// scaleRange returns the scale of a question, 1 to 5 unless its meta picks another.
+func scaleRange(meta string) (int, int) {
+	if r, ok := scaleRanges[meta]; ok {
+		return r[0], r[1]
+	}
+	r := scaleRanges[constants.ScaleRange_FIVE]
+	return r[0], r[1]
+}
+
+func checkScaleRange(questionType, meta string) error {
+	if questionType != constants.QuestionType_SCALE || meta == "" {
+		return nil
+	}
+	if _, ok := scaleRanges[meta]; !ok {
+		return fmt.Errorf("scale must be %s or %s", constants.ScaleRange_FIVE, constants.ScaleRange_TEN)
+	}
+	return nil
+}
+
+// checkSelection validates an answer against the type of the question:
+// multi-select takes one or more of its answers, ranking takes all of them in
+// order and scale takes a value of the scale.
+func checkSelection(question repositories.Question, answers []repositories.Answer, answerIDs []string, value int) (SelectedAnswer, error) {
+	switch question.Type {
+	case constants.QuestionType_SCALE:
+		low, high := scaleRange(question.Meta)
+		if value < low || value > high {
+			return SelectedAnswer{}, fmt.Errorf("please pick a value from %d to %d", low, high)
+		}
+		return SelectedAnswer{
+			Selections: []repositories.CreateAnswerSelectionParams{{Value: int32(value)}},
+			AnswerID:   strconv.Itoa(value),
+		}, nil
+	case constants.QuestionType_MULTI_SELECT, constants.QuestionType_RANKING:
+	default:
+		return SelectedAnswer{}, fmt.Errorf("this question does not take several answers")
+	}
+
+	known := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		known[answer.ID] = true
+	}
+	seen := make(map[string]bool, len(answerIDs))
+	for _, id := range answerIDs {
+		if !known[id] {
+			return SelectedAnswer{}, fmt.Errorf("answer does not belong to the question")
+		}
+		if seen[id] {
+			return SelectedAnswer{}, fmt.Errorf("an answer can only be picked once")
+		}
+		seen[id] = true
+	}
+
+	selected := SelectedAnswer{
+		Selections: make([]repositories.CreateAnswerSelectionParams, 0, len(answerIDs)),
+		AnswerID:   strings.Join(answerIDs, ","),
+	}
+	if question.Type == constants.QuestionType_RANKING {
+		if len(answerIDs) != len(answers) {
+			return SelectedAnswer{}, fmt.Errorf("please rank all the answers")
+		}
+		for i, id := range answerIDs {
+			selected.Selections = append(selected.Selections, repositories.CreateAnswerSelectionParams{
+				AnswerID: id,
+				Position: int32(i + 1),
+			})
+		}
+		return selected, nil
+	}
+
+	if len(answerIDs) == 0 {
+		return SelectedAnswer{}, fmt.Errorf("please pick at least one answer")
+	}
+	// a multi-select answer is correct when it picks all the correct answers and nothing else
+	selected.Correct = true
+	hasCorrect := false
+	for _, answer := range answers {
+		hasCorrect = hasCorrect || answer.IsCorrect
+		if answer.IsCorrect != seen[answer.ID] {
+			selected.Correct = false
+		}
+	}
+	selected.Correct = selected.Correct && hasCorrect
+	for _, id := range answerIDs {
+		selected.Selections = append(selected.Selections, repositories.CreateAnswerSelectionParams{
+			AnswerID: id,
+		})
+	}
+	return selected, nil
+}
+
+func countSelections(selections []repositories.AnswerSelection) []AnswerCount {
+	counts := make(map[string]int)
+	for _, selection := range selections {
+		counts[selection.AnswerID]++
+	}
+	res := make([]AnswerCount, 0, len(counts))
+	for answerID, count := range counts {
+		res = append(res, AnswerCount{AnswerID: answerID, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].AnswerID < res[j].AnswerID
+	})
+	return res
+}
+
+func scaleStatistic(question repositories.Question, selections []repositories.AnswerSelection) ScaleStatistic {
+	low, high := scaleRange(question.Meta)
+	stat := ScaleStatistic{
+		QuestionID: question.ID,
+		Min:        low,
+		Max:        high,
+		Histogram:  make([]ScaleCount, 0, high-low+1),
+	}
+	values := make([]int, 0, len(selections))
+	counts := make(map[int]int)
+	sum := 0
+	for _, selection := range selections {
+		value := int(selection.Value)
+		values = append(values, value)
+		counts[value]++
+		sum += value
+	}
+	for value := low; value <= high; value++ {
+		stat.Histogram = append(stat.Histogram, ScaleCount{Value: value, Count: counts[value]})
+	}
+
+	stat.Responses = len(values)
+	if stat.Responses == 0 {
+		return stat
+	}
+	sort.Ints(values)
+	stat.Mean = roundStatistic(float64(sum) / float64(len(values)))
+	if mid := len(values) / 2; len(values)%2 == 0 {
+		stat.Median = float64(values[mid-1]+values[mid]) / 2
+	} else {
+		stat.Median = float64(values[mid])
+	}
+	return stat
+}
+
+// rankAnswers orders the answers of a ranking question by their average
+// position, ties keep the order of the question.
+func rankAnswers(answers []repositories.Answer, selections []repositories.AnswerSelection) []RankingResult {
+	sums := make(map[string]int)
+	responses := make(map[string]int)
+	for _, selection := range selections {
+		sums[selection.AnswerID] += int(selection.Position)
+		responses[selection.AnswerID]++
+	}
+
+	res := make([]RankingResult, 0, len(answers))
+	for _, answer := range answers {
+		result := RankingResult{
+			AnswerID:  answer.ID,
+			Responses: responses[answer.ID],
+		}
+		if result.Responses > 0 {
+			result.AveragePosition = roundStatistic(float64(sums[answer.ID]) / float64(result.Responses))
+		}
+		res = append(res, result)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		// answers nobody ranked go last
+		if (res[i].Responses == 0) != (res[j].Responses == 0) {
+			return res[j].Responses == 0
+		}
+		return res[i].AveragePosition < res[j].AveragePosition
+	})
+	return res
+}
+
+func roundStatistic(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// SaveAnswerSelections keeps the selections of a participant with the points
+// they scored.
+func (s *SlideService) SaveAnswerSelections(sessionID, participant, username, slideID, questionID string, selected SelectedAnswer, points int) error {
+	return s.DB.SaveAnswerSelectionsTx(context.Background(), repositories.SaveAnswerSelectionsTxParams{
+		SessionID:   sessionID,
+		Participant: participant,
+		Username:    username,
+		SlideID:     slideID,
+		QuestionID:  questionID,
+		Selections:  selected.Selections,
+		AnswerID:    selected.AnswerID,
+		Points:      int32(points),
+	})
+}
+
+// CheckSelection validates an answer to a multi-select, scale or ranking question.
+func (s *SlideService) CheckSelection(question repositories.Question, answerIDs []string, value int) (SelectedAnswer, error) {
+	answers, err := s.DB.GetAnswersByQuestion(context.Background(), question.ID)
+	if err != nil {
+		return SelectedAnswer{}, err
+	}
+	return checkSelection(question, answers, answerIDs, value)
+}
+
+// GetQuestionStatistic aggregates the answers to any question that is not a
+// paragraph into the event the room is sent.
+func (s *SlideService) GetQuestionStatistic(sessionID string, question repositories.Question) (string, interface{}, error) {
+	if isSelectionType(question.Type) {
+		return s.GetSelectionStatistic(sessionID, question)
+	}
+	counts, err := s.CountAnswerByQuestionID(sessionID, question.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	return "showStatistic", AnswerStatistic{QuestionID: question.ID, Counts: counts}, nil
+}
+
+// GetSelectionStatistic aggregates the answers to a multi-select, scale or
+// ranking question into the event the room is sent.
+func (s *SlideService) GetSelectionStatistic(sessionID string, question repositories.Question) (string, interface{}, error) {
+	cctx := context.Background()
+	selections, err := s.DB.ListAnswerSelectionsByQuestion(cctx, repositories.ListAnswerSelectionsByQuestionParams{
+		SessionID:  sessionID,
+		QuestionID: question.ID,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch question.Type {
+	case constants.QuestionType_SCALE:
+		return "scaleStatistic", scaleStatistic(question, selections), nil
+	case constants.QuestionType_RANKING:
+		answers, err := s.DB.GetAnswersByQuestion(cctx, question.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		return "rankingStatistic", RankingStatistic{QuestionID: question.ID, Results: rankAnswers(answers, selections)}, nil
+	default:
+		return "showStatistic", AnswerStatistic{QuestionID: question.ID, Counts: countSelections(selections)}, nil
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+func testAnswers(correct ...bool) []repositories.Answer {
+	answers := make([]repositories.Answer, 0, len(correct))
+	for i, c := range correct {
+		answers = append(answers, repositories.Answer{Answer: entities.Answer{
+			ID:        string(rune('a' + i)),
+			Index:     int16(i + 1),
+			IsCorrect: c,
+		}})
+	}
+	return answers
+}
+
+func testQuestion(questionType, meta string) repositories.Question {
+	return repositories.Question{Question: entities.Question{ID: "question", Type: questionType, Meta: meta}}
+}
+
+func testSelections(rows ...repositories.CreateAnswerSelectionParams) []repositories.AnswerSelection {
+	selections := make([]repositories.AnswerSelection, 0, len(rows))
+	for _, row := range rows {
+		selections = append(selections, repositories.AnswerSelection{AnswerSelection: entities.AnswerSelection{
+			AnswerID: row.AnswerID,
+			Position: row.Position,
+			Value:    row.Value,
+		}})
+	}
+	return selections
+}
+
+func TestCheckMultiSelect(t *testing.T) {
+	question := testQuestion(constants.QuestionType_MULTI_SELECT, "")
+	answers := testAnswers(true, false, true)
+
+	_, err := checkSelection(question, answers, nil, 0)
+	require.Error(t, err)
+	_, err = checkSelection(question, answers, []string{"a", "z"}, 0)
+	require.Error(t, err)
+	_, err = checkSelection(question, answers, []string{"a", "a"}, 0)
+	require.Error(t, err)
+
+	selected, err := checkSelection(question, answers, []string{"c", "a"}, 0)
+	require.NoError(t, err)
+	require.True(t, selected.Correct)
+	require.Equal(t, "c,a", selected.AnswerID)
+	require.Len(t, selected.Selections, 2)
+
+	// picking only some of the correct answers is wrong
+	selected, err = checkSelection(question, answers, []string{"a"}, 0)
+	require.NoError(t, err)
+	require.False(t, selected.Correct)
+	selected, err = checkSelection(question, answers, []string{"a", "b", "c"}, 0)
+	require.NoError(t, err)
+	require.False(t, selected.Correct)
+
+	_, err = checkSelection(testQuestion(constants.QuestionType_MULTIPLE_CHOICE, ""), answers, []string{"a"}, 0)
+	require.Error(t, err)
+}
+
+func TestCheckRanking(t *testing.T) {
+	question := testQuestion(constants.QuestionType_RANKING, "")
+	answers := testAnswers(false, false, false)
+
+	_, err := checkSelection(question, answers, []string{"a", "b"}, 0)
+	require.Error(t, err)
+	selected, err := checkSelection(question, answers, []string{"c", "a", "b"}, 0)
+	require.NoError(t, err)
+	require.Equal(t, []repositories.CreateAnswerSelectionParams{
+		{AnswerID: "c", Position: 1},
+		{AnswerID: "a", Position: 2},
+		{AnswerID: "b", Position: 3},
+	}, selected.Selections)
+
+	// ties keep the order of the question
+	require.Equal(t, []RankingResult{
+		{AnswerID: "a", AveragePosition: 1.5, Responses: 2},
+		{AnswerID: "c", AveragePosition: 1.5, Responses: 2},
+		{AnswerID: "b", AveragePosition: 3, Responses: 2},
+	}, rankAnswers(answers, testSelections(
+		repositories.CreateAnswerSelectionParams{AnswerID: "c", Position: 1},
+		repositories.CreateAnswerSelectionParams{AnswerID: "a", Position: 2},
+		repositories.CreateAnswerSelectionParams{AnswerID: "b", Position: 3},
+		repositories.CreateAnswerSelectionParams{AnswerID: "a", Position: 1},
+		repositories.CreateAnswerSelectionParams{AnswerID: "c", Position: 2},
+		repositories.CreateAnswerSelectionParams{AnswerID: "b", Position: 3},
+	)))
+}
+
+func TestScale(t *testing.T) {
+	require.NoError(t, checkScaleRange(constants.QuestionType_SCALE, ""))
+	require.NoError(t, checkScaleRange(constants.QuestionType_SCALE, constants.ScaleRange_TEN))
+	require.Error(t, checkScaleRange(constants.QuestionType_SCALE, "1-7"))
+	require.NoError(t, checkScaleRange(constants.QuestionType_PARAGRAPH, "1-7"))
+
+	five := testQuestion(constants.QuestionType_SCALE, "")
+	_, err := checkSelection(five, nil, nil, 0)
+	require.Error(t, err)
+	_, err = checkSelection(five, nil, nil, 6)
+	require.Error(t, err)
+	selected, err := checkSelection(five, nil, nil, 4)
+	require.NoError(t, err)
+	require.Equal(t, "4", selected.AnswerID)
+	ten := testQuestion(constants.QuestionType_SCALE, constants.ScaleRange_TEN)
+	_, err = checkSelection(ten, nil, nil, 0)
+	require.NoError(t, err)
+
+	stat := scaleStatistic(five, testSelections(
+		repositories.CreateAnswerSelectionParams{Value: 1},
+		repositories.CreateAnswerSelectionParams{Value: 4},
+		repositories.CreateAnswerSelectionParams{Value: 5},
+		repositories.CreateAnswerSelectionParams{Value: 4},
+	))
+	require.Equal(t, ScaleStatistic{
+		QuestionID: "question",
+		Min:        1,
+		Max:        5,
+		Responses:  4,
+		Mean:       3.5,
+		Median:     4,
+		Histogram: []ScaleCount{
+			{Value: 1, Count: 1},
+			{Value: 2},
+			{Value: 3},
+			{Value: 4, Count: 2},
+			{Value: 5, Count: 1},
+		},
+	}, stat)
+
+	empty := scaleStatistic(ten, nil)
+	require.Zero(t, empty.Responses)
+	require.Len(t, empty.Histogram, 11)
+}
+
+func TestCountSelections(t *testing.T) {
+	require.Equal(t, []AnswerCount{
+		{AnswerID: "b", Count: 2},
+		{AnswerID: "a", Count: 1},
+	}, countSelections(testSelections(
+		repositories.CreateAnswerSelectionParams{AnswerID: "a"},
+		repositories.CreateAnswerSelectionParams{AnswerID: "b"},
+		repositories.CreateAnswerSelectionParams{AnswerID: "b"},
+	)))
+}
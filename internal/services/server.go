@@ -15,6 +15,7 @@ type Server struct {
 	QuestionService     *QuestionService
 	AnswerService       *AnswerService
 	UserQuestionService *UserQuestionService
+	PresentationService *PresentationService
 }
 
 func NewServer(store repositories.Store, c *utils.Config) *Server {
@@ -36,6 +37,11 @@ func NewServer(store repositories.Store, c *utils.Config) *Server {
 	questionService := NewQuestionService(store, c)
 	answerService := NewAnswerService(store, c)
 	userQuestionService := NewUserQuestionService(store, c)
+	sessionStore, err := NewSessionStore(store, c.SessionStore)
+	if err != nil {
+		panic(err)
+	}
+	presentationService := NewPresentationService(store, sessionStore, slideService, c)
 
 	return &Server{
 		AuthService:         authService,
@@ -45,5 +51,6 @@ func NewServer(store repositories.Store, c *utils.Config) *Server {
 		QuestionService:     questionService,
 		AnswerService:       answerService,
 		UserQuestionService: userQuestionService,
+		PresentationService: presentationService,
 	}
 }
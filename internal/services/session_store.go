@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+// SessionStore keeps the state of live presentations. The memory store only
+// works for a single instance, the Postgres store is shared by every instance.
+type SessionStore interface {
+	// Host adds the host to a room, creating the room on the first question when
+	// it is not running yet. It reports whether the room was created.
+	Host(ctx context.Context, roomID string, host Participant, isGroup bool, groupID string) (bool, error)
+	// Join adds a participant to a room or re-activates one that has left. It
+	// returns ErrRoomNotFound when nobody is hosting the room.
+	Join(ctx context.Context, roomID string, participant Participant) error
+	// Disconnect marks every participant of the connection as left and closes the
+	// rooms nobody is active in anymore. It returns the IDs of the closed rooms.
+	Disconnect(ctx context.Context, sid string) ([]string, error)
+	// Get returns a copy of the room state.
+	Get(ctx context.Context, roomID string) (LiveRoom, bool, error)
+	// Update applies fn to the room atomically and returns the updated copy. The
+	// room is left untouched when fn fails.
+	Update(ctx context.Context, roomID string, fn func(r *LiveRoom) error) (LiveRoom, error)
+	Remove(ctx context.Context, roomID string) error
+	// ActiveRoomIDs lists the rooms that have at least one participant.
+	ActiveRoomIDs(ctx context.Context) ([]string, error)
+	// GroupPresentation returns the room presenting to a group.
+	GroupPresentation(ctx context.Context, groupID string) (string, bool, error)
+	// AssignPin gives a running room a short numeric PIN participants can type in
+	// instead of the slide ID. A room keeps its PIN until it is closed.
+	AssignPin(ctx context.Context, roomID string) (string, error)
+	// ResolvePin returns the running room a PIN belongs to.
+	ResolvePin(ctx context.Context, pin string) (string, bool, error)
+}
+
+func NewSessionStore(db repositories.Store, kind string) (SessionStore, error) {
+	switch kind {
+	case "", constants.SessionStore_MEMORY:
+		return NewRoomManager(), nil
+	case constants.SessionStore_POSTGRES:
+		return NewPostgresSessionStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown session store: %s", kind)
+	}
+}
+
+func activeParticipants(ctx context.Context, rooms SessionStore, roomID string) ([]Participant, error) {
+	r, ok, err := rooms.Get(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []Participant{}, nil
+	}
+	return r.ActiveParticipants(), nil
+}
+
+// currentSession returns the presentation session the room is running.
+func currentSession(ctx context.Context, rooms SessionStore, roomID string) (string, error) {
+	r, ok, err := rooms.Get(ctx, roomID)
+	if err != nil {
+		return "", err
+	}
+	if !ok || r.SessionID == "" {
+		return "", errNotRunning
+	}
+	return r.SessionID, nil
+}
+
+func checkTeacher(ctx context.Context, rooms SessionStore, roomID, userID string) error {
+	r, ok, err := rooms.Get(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNotInRoom
+	}
+	return r.CheckTeacher(userID)
+}
+
+func submitAnswer(ctx context.Context, rooms SessionStore, roomID, key, questionID, answerID string) error {
+	_, err := rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		return r.submitAnswer(key, questionID, answerID)
+	})
+	if errors.Is(err, ErrRoomNotFound) {
+		return errNotInRoom
+	}
+	return err
+}
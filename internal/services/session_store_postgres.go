@@ -0,0 +1,204 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+// PostgresSessionStore keeps every room as a JSON document in the live_room
+// table, so all instances behind a load balancer see the same state.
+type PostgresSessionStore struct {
+	DB repositories.Store
+}
+
+var _ SessionStore = (*PostgresSessionStore)(nil)
+
+func NewPostgresSessionStore(db repositories.Store) *PostgresSessionStore {
+	return &PostgresSessionStore{DB: db}
+}
+
+// update runs fn on the locked room. A missing room is created when create is
+// set, otherwise ErrRoomNotFound is returned.
+func (p *PostgresSessionStore) update(ctx context.Context, roomID string, create bool, fn func(r *LiveRoom, created bool) error) (LiveRoom, error) {
+	var room LiveRoom
+	err := p.DB.UpdateLiveRoomTx(ctx, roomID, func(data []byte) ([]byte, error) {
+		r, ok, err := decodeLiveRoom(data)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if !create {
+				return nil, ErrRoomNotFound
+			}
+			r = LiveRoom{ID: roomID}
+		}
+		if err := fn(&r, !ok); err != nil {
+			return nil, err
+		}
+		room = r
+		return json.Marshal(r)
+	})
+	if err != nil {
+		return LiveRoom{}, err
+	}
+	return room, nil
+}
+
+func decodeLiveRoom(data []byte) (LiveRoom, bool, error) {
+	var r LiveRoom
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return r, false, nil
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, false, err
+	}
+	return r, true, nil
+}
+
+func (p *PostgresSessionStore) Host(ctx context.Context, roomID string, host Participant, isGroup bool, groupID string) (bool, error) {
+	created := false
+	_, err := p.update(ctx, roomID, true, func(r *LiveRoom, isNew bool) error {
+		if isNew {
+			created = true
+			r.State = 1
+		}
+		r.addHost(host, isGroup, groupID)
+		return nil
+	})
+	return created, err
+}
+
+func (p *PostgresSessionStore) Join(ctx context.Context, roomID string, participant Participant) error {
+	_, err := p.update(ctx, roomID, false, func(r *LiveRoom, isNew bool) error {
+		return r.addParticipant(participant)
+	})
+	return err
+}
+
+func (p *PostgresSessionStore) Disconnect(ctx context.Context, sid string) ([]string, error) {
+	ids, err := p.DB.ListLiveRoomIDsBySID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	closed := make([]string, 0)
+	for _, id := range ids {
+		allLeft := false
+		_, err := p.update(ctx, id, false, func(r *LiveRoom, isNew bool) error {
+			allLeft = r.leave(sid)
+			return nil
+		})
+		if errors.Is(err, ErrRoomNotFound) {
+			continue
+		}
+		if err != nil {
+			return closed, err
+		}
+		if !allLeft {
+			continue
+		}
+		if err := p.DB.DeleteLiveRoom(ctx, id); err != nil {
+			return closed, err
+		}
+		closed = append(closed, id)
+	}
+	return closed, nil
+}
+
+func (p *PostgresSessionStore) Get(ctx context.Context, roomID string) (LiveRoom, bool, error) {
+	row, err := p.DB.GetLiveRoom(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return LiveRoom{}, false, nil
+		}
+		return LiveRoom{}, false, err
+	}
+	return decodeLiveRoom(row.Data)
+}
+
+func (p *PostgresSessionStore) Update(ctx context.Context, roomID string, fn func(r *LiveRoom) error) (LiveRoom, error) {
+	return p.update(ctx, roomID, false, func(r *LiveRoom, isNew bool) error {
+		return fn(r)
+	})
+}
+
+func (p *PostgresSessionStore) Remove(ctx context.Context, roomID string) error {
+	return p.DB.DeleteLiveRoom(ctx, roomID)
+}
+
+func (p *PostgresSessionStore) ActiveRoomIDs(ctx context.Context) ([]string, error) {
+	return p.DB.ListActiveLiveRoomIDs(ctx)
+}
+
+func (p *PostgresSessionStore) GroupPresentation(ctx context.Context, groupID string) (string, bool, error) {
+	roomID, err := p.DB.GetLiveRoomIDByGroup(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return roomID, true, nil
+}
+
+func (p *PostgresSessionStore) AssignPin(ctx context.Context, roomID string) (string, error) {
+	r, ok, err := p.Get(ctx, roomID)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrRoomNotFound
+	}
+	if r.Pin != "" {
+		return r.Pin, nil
+	}
+
+	for length := minPinLength; length <= maxPinLength; length++ {
+		for i := 0; i < pinAttempts; i++ {
+			pin := randomPin(length)
+			n, err := p.DB.CreateLivePin(ctx, repositories.CreateLivePinParams{
+				Pin:    pin,
+				RoomID: roomID,
+			})
+			if err != nil {
+				return "", err
+			}
+			if n == 0 {
+				// either the PIN is taken or another instance gave the room one
+				existing, err := p.DB.GetLivePinByRoom(ctx, roomID)
+				if err == nil {
+					pin = existing.Pin
+				} else if errors.Is(err, sql.ErrNoRows) {
+					continue
+				} else {
+					return "", err
+				}
+			}
+			_, err = p.Update(ctx, roomID, func(r *LiveRoom) error {
+				r.Pin = pin
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+			return pin, nil
+		}
+	}
+	return "", ErrNoPinAvailable
+}
+
+func (p *PostgresSessionStore) ResolvePin(ctx context.Context, pin string) (string, bool, error) {
+	row, err := p.DB.GetLivePin(ctx, pin)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return row.RoomID, true, nil
+}
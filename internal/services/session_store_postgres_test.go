@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+// newTestStore connects to the database of app.env and skips the test when it
+// is not reachable.
+func newTestStore(t *testing.T) (repositories.Store, utils.Config) {
+	config, err := utils.LoadConfig("../../")
+	if err != nil {
+		t.Skip("can't load config:", err)
+	}
+	db, err := sql.Open(config.DBDriver, config.DBUrl)
+	if err != nil {
+		t.Skip("cannot connect to db:", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		t.Skip("cannot connect to db:", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return repositories.NewStore(db), config
+}
+
+func TestPostgresSessionStore(t *testing.T) {
+	db, _ := newTestStore(t)
+
+	t.Run("ConcurrentJoinSubmitDisconnect", func(t *testing.T) {
+		testConcurrentJoinSubmitDisconnect(t, NewPostgresSessionStore(db))
+	})
+	t.Run("ClosesRoomWhenAllLeft", func(t *testing.T) {
+		testClosesRoomWhenAllLeft(t, NewPostgresSessionStore(db))
+	})
+	t.Run("UpdateIsAtomic", func(t *testing.T) {
+		testUpdateIsAtomic(t, NewPostgresSessionStore(db))
+	})
+	t.Run("GuestRejoin", func(t *testing.T) {
+		testGuestRejoin(t, NewPostgresSessionStore(db))
+	})
+	t.Run("KickAndBan", func(t *testing.T) {
+		testKickAndBan(t, NewPostgresSessionStore(db))
+	})
+	t.Run("LobbyAndCap", func(t *testing.T) {
+		testLobbyAndCap(t, NewPostgresSessionStore(db))
+	})
+}
+
+func TestPostgresSessionStoreSharedBetweenInstances(t *testing.T) {
+	db, _ := newTestStore(t)
+	ctx := context.Background()
+	first := NewPostgresSessionStore(db)
+	second := NewPostgresSessionStore(db)
+
+	roomID := utils.RandomString(12)
+	created, err := first.Host(ctx, roomID, Participant{Username: "teacher", SID: "teacher-sid-" + roomID}, false, "")
+	require.NoError(t, err)
+	require.True(t, created)
+	pin, err := first.AssignPin(ctx, roomID)
+	require.NoError(t, err)
+
+	resolved, ok, err := second.ResolvePin(ctx, pin)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, roomID, resolved)
+	require.NoError(t, second.Join(ctx, roomID, Participant{Username: "student", SID: "student-sid-" + roomID}))
+
+	_, err = second.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.State = 3
+		r.IsQuiz = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	r, ok, err := first.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 3, r.State)
+	require.True(t, r.IsQuiz)
+	require.Len(t, r.ActiveParticipants(), 2)
+
+	again, err := second.AssignPin(ctx, roomID)
+	require.NoError(t, err)
+	require.Equal(t, pin, again)
+
+	require.NoError(t, first.Remove(ctx, roomID))
+	_, ok, err = second.Get(ctx, roomID)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -2,300 +2,1165 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	socketio "github.com/googollee/go-socket.io"
 	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
 	"github.com/vtv-us/kahoot-backend/internal/repositories"
 )
 
+// RoomContext is the identity of a connection. UserID and Username are only
+// set from a verified access token, guests have no UserID. Names are not
+// unique, so what the connection submits is kept by its participant key and
+// Username is only shown.
 type RoomContext struct {
-	Username  string
+	UserID   string
+	Username string
+	// GuestID is the ID of the guest the connection joined as
+	GuestID   string
 	RoomID    string
 	IsTeacher bool
+	// Protocol is the version of the socket protocol the connection speaks
+	Protocol int
 }
 
-type Participant struct {
-	Username  string
-	IsTeacher bool
-	Status    string
-	SID       string
-	// [Question index] -> Answer
-	Answer map[int]int
+type PresentationNotification struct {
+	SlideID string `json:"slide_id"`
+	GroupID string `json:"group_id"`
 }
 
-type ChatMessage struct {
-	Username string
-	Message  string
-}
+func InitSocketServer(server *Server) *socketio.Server {
 
-// [ID] -> List of participants
-type Room map[string][]Participant
-type IsRoomGroup map[string]bool
-type RoomGroup map[string]string
-type RoomState map[string]int
-type GroupSlidePresent map[string]string
+	socket := socketio.NewServer(nil)
 
-type PresentationNotification struct {
-	SlideID string
-	GroupID string
-}
+	rooms := server.PresentationService.Rooms
+	broadcaster, err := NewBroadcaster(server.PresentationService.DB, server.PresentationService.Config, socket, server.PresentationService.Feed)
+	if err != nil {
+		panic(err)
+	}
 
-var room Room
+	// onEvent registers a handler behind the rate limit and the argument
+	// checks of its event, the handler has to match the spec of the event.
+	guard := newEventGuard()
+	onEvent := func(namespace, event string, f interface{}) {
+		if err := checkEventSpec(namespace, event, f); err != nil {
+			panic(err)
+		}
+		socket.OnEvent(namespace, event, guard.wrap(event, f))
+	}
 
-func InitSocketServer(server *Server) *socketio.Server {
+	reactionBatch := newReactionBatcher()
+	go runReactionBatcher(server, broadcaster, reactionBatch)
 
-	socket := socketio.NewServer(nil)
+	// publishTeamLeaderboard sends the team scores to rooms playing in teams.
+	publishTeamLeaderboard := func(r LiveRoom) error {
+		if r.TeamMode == "" || r.SessionID == "" {
+			return nil
+		}
+		leaderboard, err := server.SlideService.GetTeamLeaderboard(r.SessionID)
+		if err != nil {
+			return err
+		}
+		broadcaster.BroadcastToRoom("/", r.ID, "teamLeaderboard", TeamLeaderboard{Entries: leaderboard})
+		return nil
+	}
 
-	room = make(Room)
-	roomState := make(RoomState)
-	isRoomGroup := make(IsRoomGroup)
-	roomGroup := make(RoomGroup)
-	groupSlidePresent := make(GroupSlidePresent)
+	// quiz rooms show the leaderboard after every question
+	onQuestionClosed := func(roomID, questionID string) {
+		r, ok, err := rooms.Get(context.Background(), roomID)
+		if err != nil || !ok || !r.IsQuiz {
+			return
+		}
+		leaderboard, err := server.SlideService.GetLeaderboard(r.SessionID)
+		if err != nil {
+			fmt.Println("get leaderboard failed:", err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "leaderboard", Leaderboard{Entries: leaderboard})
+		if err := publishTeamLeaderboard(r); err != nil {
+			fmt.Println("get team leaderboard failed:", err)
+		}
+	}
+
+	timers := newQuestionTimers(server, broadcaster, onQuestionClosed)
+
+	// closeRoom ends the session of a room that has been removed and tells the
+	// room, and its feeds, that it is gone.
+	closeRoom := func(roomID string) {
+		timers.stop(roomID)
+		if err := server.PresentationService.EndSessions(context.Background(), roomID); err != nil {
+			fmt.Println("end presentation session failed:", err)
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "roomClosed", RoomRef{RoomID: roomID})
+	}
+
+	// publishStatistic sends the results of a question to the room once they are
+	// revealed and only to its hosts before. Multi-select, scale and ranking
+	// questions have their own statistic.
+	publishStatistic := func(r LiveRoom, questionID string) error {
+		question, err := server.QuestionService.DB.GetQuestion(context.Background(), questionID)
+		if err != nil {
+			return err
+		}
+		event, statistic, err := server.SlideService.GetQuestionStatistic(r.SessionID, question)
+		if err != nil {
+			return err
+		}
+		result, err := server.SlideService.ListAnswerHistoryByQuestionID(r.SessionID, questionID)
+		if err != nil {
+			return err
+		}
+		to := hostRoom(r.ID)
+		if resultsRevealed(r, questionID) {
+			to = r.ID
+		}
+		broadcaster.BroadcastToRoom("/", to, event, statistic)
+		broadcaster.BroadcastToRoom("/", to, "resultList", ResultList{QuestionID: questionID, Answers: result})
+		return nil
+	}
+
+	// publishWordCloud sends the word cloud of a paragraph question like
+	// publishStatistic sends the results of the others.
+	publishWordCloud := func(r LiveRoom, questionID string) error {
+		cloud, err := server.SlideService.GetWordCloud(r.SessionID, questionID, r.StemWords)
+		if err != nil {
+			return err
+		}
+		to := hostRoom(r.ID)
+		if resultsRevealed(r, questionID) {
+			to = r.ID
+		}
+		broadcaster.BroadcastToRoom("/", to, "wordCloud", cloud)
+		return nil
+	}
 
 	socket.OnConnect("/", func(s socketio.Conn) error {
 		fmt.Println("connected:", s.ID())
-		return nil
+		ctx := &RoomContext{}
+		s.SetContext(ctx)
+		protocol, err := negotiateProtocol(s)
+		if err != nil {
+			return err
+		}
+		ctx.Protocol = protocol
+		s.Join(connRoom(s.ID()))
+		s.Emit("protocol", ProtocolInfo{
+			Version:    protocol,
+			MinVersion: minProtocolVersion,
+			MaxVersion: ProtocolVersion,
+		})
+		// clients may also send the token with their first host or join event
+		return ctx.authenticate(server, handshakeToken(s))
 	})
 
-	socket.OnEvent("/", "getRoomActive", func(s socketio.Conn) {
-		ids := make([]string, 0)
-		for id, participants := range room {
-			if len(participants) > 0 {
-				ids = append(ids, id)
-			}
+	onEvent("/", "getRoomActive", func(s socketio.Conn) {
+		ids, err := rooms.ActiveRoomIDs(context.Background())
+		if err != nil {
+			emitError(s, err)
+			return
 		}
-		s.Emit("getRoomActive", ids)
+		s.Emit("getRoomActive", RoomList{RoomIDs: ids})
 	})
-	socket.OnEvent("/", "getActiveParticipants", func(s socketio.Conn) {
+	onEvent("/", "getActiveParticipants", func(s socketio.Conn) {
 		ctx := s.Context().(*RoomContext)
-		activeParticipants := make([]Participant, 0)
-		for _, participant := range room[ctx.RoomID] {
-			if participant.Status == constants.SocketParticipantStatus_ACTIVE {
-				activeParticipants = append(activeParticipants, participant)
-			}
+		participants, err := activeParticipants(context.Background(), rooms, ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return
 		}
-		s.Emit("getActiveParticipants", activeParticipants)
+		s.Emit("getActiveParticipants", participantList(participants))
 	})
 
-	socket.OnEvent("/", "manualDisconnect", func(s socketio.Conn) {
+	onEvent("/", "manualDisconnect", func(s socketio.Conn) {
 		s.Close()
 	})
 
-	socket.OnEvent("/", "host", func(s socketio.Conn, username, roomID string, isGroup bool, groupID string, token string) {
-		isRoomGroup[roomID] = isGroup
-		if isGroup {
-			err := checkUserInGroup(server, groupID, token)
+	onEvent("/", "host", func(s socketio.Conn, req HostRequest) {
+		ctx, err := identify(server, s, req.Token)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if ctx.UserID == "" {
+			emitError(s, errSocketUnauthenticated)
+			return
+		}
+		cctx := context.Background()
+		roomID, groupID := req.RoomID, req.GroupID
+		if err := checkPresentPermission(cctx, server.SlideService.DB, roomID, ctx.UserID); err != nil {
+			emitError(s, err)
+			return
+		}
+		if req.IsGroup {
+			err := checkUserInGroup(server, groupID, ctx.UserID)
 			if err != nil {
-				s.Emit("error", err.Error())
+				emitError(s, err)
 				return
 			}
-			roomGroup[roomID] = groupID
-			groupSlidePresent[groupID] = roomID
-			socket.BroadcastToRoom("/notification", groupID, "notify", PresentationNotification{
+		}
+		ctx.RoomID = roomID
+		ctx.IsTeacher = true
+		fmt.Println(s.ID(), ctx.Username, "host:", roomID)
+		created, err := rooms.Host(cctx, roomID, Participant{
+			Username: ctx.Username,
+			UserID:   ctx.UserID,
+			SID:      s.ID(),
+		}, req.IsGroup, groupID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if !req.IsGroup {
+			groupID = ""
+		}
+		if err := server.PresentationService.ensureSession(cctx, roomID, ctx.UserID, groupID); err != nil {
+			emitError(s, err)
+			return
+		}
+		if created {
+			maxParticipants := req.MaxParticipants
+			if maxParticipants <= 0 {
+				maxParticipants = server.PresentationService.Config.MaxParticipants
+			}
+			_, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+				r.Lobby = req.Lobby
+				r.MaxParticipants = maxParticipants
+				return nil
+			})
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			timers.start(roomID, 1)
+		}
+		if req.IsGroup {
+			broadcaster.BroadcastToRoom("/notification", groupID, "notify", PresentationNotification{
 				SlideID: roomID,
 				GroupID: groupID,
 			})
 		}
-		ctx := &RoomContext{
-			Username:  username,
-			RoomID:    roomID,
-			IsTeacher: true,
+		s.Join(roomID)
+		s.Join(hostRoom(roomID))
+
+		pin, err := rooms.AssignPin(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
 		}
-		s.SetContext(ctx)
-		fmt.Println(s.ID(), username, "host:", roomID)
-		if _, ok := room[roomID]; !ok {
-			roomState[roomID] = 1
-		}
-		// check if room already has a teacher
-		// for _, participant := range room[roomID] {
-		// 	if participant.IsTeacher && participant.Username != username {
-		// 		s.Emit("error", "Room already has a teacher")
-		// 		return
-		// 	}
-		// }
-
-		exist := checkExistInRoom(username, roomID)
-		if exist {
-			for i, participant := range room[roomID] {
-				if participant.Username == username {
-					room[roomID][i].Status = constants.SocketParticipantStatus_ACTIVE
-					room[roomID][i].SID = s.ID()
-				}
+		s.Emit("gamePin", GamePin{Pin: pin})
+	})
+
+	// resume sends a reconnected participant the whole state of the room at once.
+	onEvent("/", "resume", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		snapshot, err := server.PresentationService.Resume(context.Background(), ctx.RoomID, ctx.participant())
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("resume", snapshot)
+	})
+
+	onEvent("/", "getRoomState", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		r, _, err := rooms.Get(context.Background(), ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("getRoomState", r.roomState())
+	})
+
+	// moveQuestion changes the question the room is on and opens it for answering.
+	// The question moved away from is closed in quiz rooms and when it is timed.
+	moveQuestion := func(s socketio.Conn, move func(r *LiveRoom) error) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			if r.Paced {
+				return errPaced
 			}
-		} else {
-			room[roomID] = append(room[roomID], Participant{
-				IsTeacher: true,
-				Username:  username,
-				Status:    constants.SocketParticipantStatus_ACTIVE,
-				SID:       s.ID(),
+			return move(r)
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if r.IsQuiz || !r.Question.Deadline.IsZero() {
+			timers.stop(roomID)
+			questionID, ok, err := closeCurrentQuestion(cctx, rooms, roomID)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			if ok {
+				broadcaster.BroadcastToRoom("/", roomID, "questionClosed", QuestionClosed{
+					QuestionID: questionID,
+				})
+				onQuestionClosed(roomID, questionID)
+			}
+		}
+		timers.start(roomID, r.State)
+		r, _, err = rooms.Get(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "getRoomState", r.roomState())
+	}
+
+	// controlQuestion changes what the audience can do with the current question.
+	controlQuestion := func(s socketio.Conn, control func(r *LiveRoom) error) (LiveRoom, bool) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return LiveRoom{}, false
+		}
+		r, err := rooms.Update(cctx, roomID, control)
+		if err != nil {
+			emitError(s, err)
+			return LiveRoom{}, false
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "getRoomState", r.roomState())
+		return r, true
+	}
+
+	// kickParticipant removes a participant from the room and closes its
+	// connection, with ban it cannot join again for the rest of the session.
+	onEvent("/", "kickParticipant", func(s socketio.Conn, req KickRequest) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		var kicked Participant
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			var err error
+			kicked, err = r.kick(req.Participant, req.Ban)
+			return err
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if kicked.SID != "" {
+			broadcaster.BroadcastToRoom("/", connRoom(kicked.SID), kickedEvent, KickNotice{
+				RoomID: roomID,
+				Banned: req.Ban,
+			})
+		}
+		if r.SessionID != "" {
+			if err := server.PresentationService.RecordKick(cctx, r.SessionID, kicked, req.Ban, ctx.UserID); err != nil {
+				emitError(s, fmt.Errorf("record kick failed: %w", err))
+				return
+			}
+		}
+		broadcaster.BroadcastToRoom("/", hostRoom(roomID), "participantKicked", kicked.info())
+	})
+
+	onEvent("/", "lockAnswers", func(s socketio.Conn) {
+		controlQuestion(s, func(r *LiveRoom) error {
+			return r.setAnswersLocked(true)
+		})
+	})
+
+	onEvent("/", "unlockAnswers", func(s socketio.Conn) {
+		controlQuestion(s, func(r *LiveRoom) error {
+			return r.setAnswersLocked(false)
+		})
+	})
+
+	onEvent("/", "revealResults", func(s socketio.Conn) {
+		r, ok := controlQuestion(s, func(r *LiveRoom) error {
+			return r.setResultsRevealed(true)
+		})
+		if !ok || r.SessionID == "" {
+			return
+		}
+		if err := publishStatistic(r, r.Question.QuestionID); err != nil {
+			emitError(s, err)
+			return
+		}
+		question, err := server.QuestionService.DB.GetQuestion(context.Background(), r.Question.QuestionID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if question.Type == constants.QuestionType_PARAGRAPH {
+			if err := publishWordCloud(r, question.ID); err != nil {
+				emitError(s, err)
+			}
+		}
+	})
+
+	onEvent("/", "hideResults", func(s socketio.Conn) {
+		controlQuestion(s, func(r *LiveRoom) error {
+			return r.setResultsRevealed(false)
+		})
+	})
+
+	onEvent("/", "setRoomState", func(s socketio.Conn, req SetRoomStateRequest) {
+		moveQuestion(s, func(r *LiveRoom) error {
+			r.State = req.State
+			return nil
+		})
+	})
+
+	onEvent("/", "next", func(s socketio.Conn) {
+		moveQuestion(s, func(r *LiveRoom) error {
+			r.State++
+			return nil
+		})
+	})
+
+	onEvent("/", "prev", func(s socketio.Conn) {
+		moveQuestion(s, func(r *LiveRoom) error {
+			if r.State <= 1 {
+				return fmt.Errorf("You are at the first question")
+			}
+			r.State--
+			return nil
+		})
+	})
+
+	onEvent("/", "setQuizMode", func(s socketio.Conn, req Toggle) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			r.IsQuiz = req.Enabled
+			return nil
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "quizMode", req)
+	})
+
+	onEvent("/", "endQuiz", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		timers.stop(roomID)
+		questionID, ok, err := closeCurrentQuestion(cctx, rooms, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if ok {
+			broadcaster.BroadcastToRoom("/", roomID, "questionClosed", QuestionClosed{
+				QuestionID: questionID,
 			})
 		}
+		sessionID, err := currentSession(cctx, rooms, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		podium, err := server.SlideService.GetPodium(sessionID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "podium", Leaderboard{Entries: podium})
+		r, _, err := rooms.Get(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if err := publishTeamLeaderboard(r); err != nil {
+			emitError(s, err)
+		}
+	})
+
+	// publishPaceGrid keeps the hosts of a student-paced room up to date with
+	// where every participant is.
+	publishPaceGrid := func(r LiveRoom) {
+		broadcaster.BroadcastToRoom("/", hostRoom(r.ID), "paceProgress", r.paceGrid())
+	}
+
+	// setPacedMode lets every participant move through the questions at its own
+	// pace. The shared question is closed, answers go to the question each
+	// participant is on.
+	onEvent("/", "setPacedMode", func(s socketio.Conn, req Toggle) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		var questions []PacedQuestion
+		if req.Enabled {
+			res, err := server.QuestionService.DB.GetQuestionsBySlide(cctx, roomID)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			questions = pacedQuestions(res)
+		}
+		timers.stop(roomID)
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			return r.setPaced(req.Enabled, questions, time.Now())
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "pacedMode", req)
+		if req.Enabled {
+			publishPaceGrid(r)
+		}
+	})
+
+	// movePace moves the caller to its next or previous question.
+	movePace := func(s socketio.Conn, step int) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			_, err := r.movePace(ctx.participant().key(), step, time.Now())
+			return err
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("paceState", r.paceState(ctx.participant().key()))
+		publishPaceGrid(r)
+	}
+
+	onEvent("/", "paceNext", func(s socketio.Conn) {
+		movePace(s, 1)
+	})
+
+	onEvent("/", "pacePrev", func(s socketio.Conn) {
+		movePace(s, -1)
+	})
+
+	onEvent("/", "getPaceState", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		r, _, err := rooms.Get(context.Background(), ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if !r.Paced {
+			emitError(s, errNotPaced)
+			return
+		}
+		s.Emit("paceState", r.paceState(ctx.participant().key()))
+	})
+
+	onEvent("/", "getPaceProgress", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		err := checkTeacher(cctx, rooms, ctx.RoomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		r, _, err := rooms.Get(cctx, ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("paceProgress", r.paceGrid())
+	})
+
+	// endPacedSession finishes a student-paced session for everyone, quizzes
+	// show their podium.
+	onEvent("/", "endPacedSession", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			return r.endPace()
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "pacedEnded", RoomRef{RoomID: roomID})
+		publishPaceGrid(r)
+		if !r.IsQuiz || r.SessionID == "" {
+			return
+		}
+		podium, err := server.SlideService.GetPodium(r.SessionID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "podium", Leaderboard{Entries: podium})
+		if err := publishTeamLeaderboard(r); err != nil {
+			emitError(s, err)
+		}
+	})
+
+	// setTeamMode splits the room into teams, assigned automatically or picked
+	// by the participants. An empty mode turns teams off.
+	onEvent("/", "setTeamMode", func(s socketio.Conn, req TeamModeRequest) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		var changed []Participant
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			var err error
+			changed, err = r.setTeams(req.Mode, req.Teams)
+			return err
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if err := server.PresentationService.SetTeams(cctx, r.SessionID, r.Teams, changed); err != nil {
+			emitError(s, fmt.Errorf("save teams failed: %w", err))
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "teams", r.teamState())
+	})
+
+	onEvent("/", "pickTeam", func(s socketio.Conn, req PickTeamRequest) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		var picked Participant
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			var err error
+			picked, err = r.pickTeam(ctx.participant().key(), req.Team)
+			return err
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if err := server.PresentationService.SaveTeamMembers(cctx, r.SessionID, []Participant{picked}); err != nil {
+			emitError(s, fmt.Errorf("save teams failed: %w", err))
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "teams", r.teamState())
+	})
+
+	onEvent("/", "getTeams", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		r, _, err := rooms.Get(context.Background(), ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("teams", r.teamState())
+	})
+
+	onEvent("/", "getTeamLeaderboard", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		sessionID, err := currentSession(context.Background(), rooms, ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		leaderboard, err := server.SlideService.GetTeamLeaderboard(sessionID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("teamLeaderboard", TeamLeaderboard{Entries: leaderboard})
+	})
+
+	// Participants waiting in the lobby join again once they are admitted.
+	onEvent("/", "join", func(s socketio.Conn, req JoinRequest) {
+		roomID := req.RoomID
+		fmt.Println(s.ID(), "join room", roomID)
+		ctx, err := identify(server, s, req.Token)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		cctx := context.Background()
+		if isRoomPin(roomID) {
+			id, ok, err := rooms.ResolvePin(cctx, roomID)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			if !ok {
+				emitError(s, errors.New("Invalid game PIN"))
+				return
+			}
+			roomID = id
+		}
+		r, ok, err := rooms.Get(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		// only a room its host is presenting can be joined
+		if !ok || r.SessionID == "" {
+			emitError(s, ErrRoomNotFound)
+			return
+		}
+		if r.IsGroup {
+			if ctx.UserID == "" {
+				emitError(s, errSocketUnauthenticated)
+				return
+			}
+			err := checkUserInGroup(server, r.GroupID, ctx.UserID)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+		}
+		participant := Participant{
+			Username: ctx.Username,
+			UserID:   ctx.UserID,
+		}
+		isNewGuest := false
+		if ctx.UserID == "" {
+			participant, isNewGuest, err = guestParticipant(server, r.SessionID, req.Username, req.ParticipantToken)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+		}
+		participant.SID = s.ID()
+		participant.Team = req.Team
+		err = rooms.Join(cctx, roomID, participant)
+		if err != nil {
+			var roomErr *RoomError
+			if errors.As(err, &roomErr) {
+				s.Emit("joinRejected", roomErr)
+			}
+			emitError(s, err)
+			return
+		}
+		ctx.Username = participant.Username
+		ctx.GuestID = participant.ID
+		ctx.IsTeacher = false
+
+		if isNewGuest {
+			signed, err := server.AuthService.JWT.GenerateParticipantToken(r.SessionID, participant.ID, participant.Username, participantTokenExpiredTime)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			s.Emit("participantToken", ParticipantToken{Token: signed})
+		}
+
+		r, _, err = rooms.Get(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		p := r.member(participant)
+		if p != nil && p.Team != "" {
+			if err := server.PresentationService.SaveTeamMembers(cctx, r.SessionID, []Participant{*p}); err != nil {
+				emitError(s, fmt.Errorf("save teams failed: %w", err))
+				return
+			}
+			broadcaster.BroadcastToRoom("/", roomID, "teams", r.teamState())
+		}
+		if p != nil && p.Status == constants.SocketParticipantStatus_PENDING {
+			s.Emit("lobby", RoomRef{RoomID: roomID})
+			broadcaster.BroadcastToRoom("/", hostRoom(roomID), "getLobby", participantList(r.PendingParticipants()))
+			return
+		}
+		ctx.RoomID = roomID
 		s.Join(roomID)
 	})
 
-	socket.OnEvent("/", "getRoomState", func(s socketio.Conn) {
+	// admit lets participants in from the lobby.
+	admit := func(s socketio.Conn, control func(r *LiveRoom) ([]Participant, error)) {
 		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
 		roomID := ctx.RoomID
-		if _, ok := roomState[roomID]; !ok {
-			roomState[roomID] = 0
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		var admitted []Participant
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			var err error
+			admitted, err = control(r)
+			return err
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		for _, p := range admitted {
+			if p.SID != "" {
+				broadcaster.BroadcastToRoom("/", connRoom(p.SID), admittedEvent, AdmitNotice{
+					RoomID: roomID,
+				})
+			}
+		}
+		broadcaster.BroadcastToRoom("/", hostRoom(roomID), "getLobby", participantList(r.PendingParticipants()))
+	}
+
+	onEvent("/", "admitParticipant", func(s socketio.Conn, req ParticipantRef) {
+		if req.Participant == "" {
+			emitError(s, errors.New("participant not found"))
+			return
+		}
+		admit(s, func(r *LiveRoom) ([]Participant, error) {
+			return r.admit(req.Participant)
+		})
+	})
+
+	onEvent("/", "admitAll", func(s socketio.Conn) {
+		admit(s, func(r *LiveRoom) ([]Participant, error) {
+			return r.admit("")
+		})
+	})
+
+	// turning the lobby off lets everyone waiting in.
+	onEvent("/", "setLobby", func(s socketio.Conn, req Toggle) {
+		admit(s, func(r *LiveRoom) ([]Participant, error) {
+			r.Lobby = req.Enabled
+			if req.Enabled {
+				return nil, nil
+			}
+			return r.admit("")
+		})
+	})
+
+	onEvent("/", "getLobby", func(s socketio.Conn) {
+		ctx := s.Context().(*RoomContext)
+		r, ok, err := rooms.Get(context.Background(), ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if !ok {
+			emitError(s, errNotInRoom)
+			return
 		}
-		s.Emit("getRoomState", roomState[roomID])
+		if err := r.CheckTeacher(ctx.UserID); err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("getLobby", participantList(r.PendingParticipants()))
 	})
-	socket.OnEvent("/", "setRoomState", func(s socketio.Conn, state int) {
+
+	onEvent("/", "setMaxParticipants", func(s socketio.Conn, req MaxParticipantsRequest) {
 		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
 		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if req.MaxParticipants < 0 {
+			emitError(s, errors.New("the participant cap cannot be negative"))
+			return
+		}
+		_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			r.MaxParticipants = req.MaxParticipants
+			return nil
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		s.Emit("notify", Notice{Message: "The participant cap has been updated"})
+	})
+
+	onEvent("/", "cancelPresentation", func(s socketio.Conn, req CancelPresentationRequest) {
+		cctx := context.Background()
+		roomID, ok, err := rooms.GroupPresentation(cctx, req.GroupID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if !ok {
+			s.Emit("notify", Notice{Message: "Slide does not present, skip cancel"})
+			return
+		}
+		ctx, err := identify(server, s, req.Token)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if ctx.UserID == "" {
+			emitError(s, errSocketUnauthenticated)
+			return
+		}
+		err = checkUserInGroup(server, req.GroupID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if err := rooms.Remove(cctx, roomID); err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "cancelPresentation", RoomRef{RoomID: roomID})
+		closeRoom(roomID)
+	})
+
+	onEvent("/", "getSlidePresentation", func(s socketio.Conn, req GroupRef) {
+		roomID, ok, err := rooms.GroupPresentation(context.Background(), req.GroupID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if !ok {
+			emitError(s, errors.New("Group does not have any slide presentation"))
+			return
+		}
+		s.Emit("getSlidePresentation", RoomRef{RoomID: roomID})
+	})
+
+	// submitAnswer takes the answer of multiple choice questions, the answers
+	// picked in order for multi-select and ranking questions and the value of
+	// scale questions.
+	onEvent("/", "submitAnswer", func(s socketio.Conn, req SubmitAnswerRequest) {
+		ctx := s.Context().(*RoomContext)
+		question, answer := req.QuestionID, req.AnswerID
 		username := ctx.Username
-		err := checkTeacherPermission(username, roomID)
+		roomID := ctx.RoomID
+		fmt.Println("submitAnswer:", username, roomID, answer)
+		cctx := context.Background()
+		r, _, err := rooms.Get(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if r.SessionID == "" {
+			emitError(s, errNotRunning)
+			return
+		}
+		window, ok, err := participantAnswerWindow(r, ctx.participant().key(), question, time.Now())
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		q, err := server.QuestionService.DB.GetQuestion(cctx, question)
+		if err != nil || q.SlideID != roomID {
+			emitError(s, errors.New("question does not exist"))
+			return
+		}
+		if r.IsQuiz && !ok {
+			emitError(s, errors.New("this question is not open for answering"))
+			return
+		}
+		// answers are checked against the question in every room, only quizzes
+		// score them
+		points := 0
+		var selected SelectedAnswer
+		switch {
+		case q.Type == constants.QuestionType_PARAGRAPH || q.Type == constants.QuestionType_HEADING:
+			emitError(s, errors.New("this question does not take a choice"))
+			return
+		case isSelectionType(q.Type):
+			selected, err = server.SlideService.CheckSelection(q, req.Selection, req.Value)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			answer = selected.AnswerID
+			if r.IsQuiz && q.Type == constants.QuestionType_MULTI_SELECT {
+				points = scoreAnswer(selected.Correct, window.OpenedAt, window.Deadline, time.Now())
+			}
+		default:
+			correct, err := server.SlideService.CheckAnswer(question, answer)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			if r.IsQuiz {
+				points = scoreAnswer(correct, window.OpenedAt, window.Deadline, time.Now())
+			}
+		}
+		if err := submitAnswer(cctx, rooms, roomID, ctx.participant().key(), question, answer); err != nil {
+			emitError(s, err)
+			return
+		}
+		if isSelectionType(q.Type) {
+			err = server.SlideService.SaveAnswerSelections(r.SessionID, ctx.participant().key(), username, roomID, question, selected, points)
+		} else {
+			err = server.SlideService.SaveAnswerHistory(r.SessionID, ctx.participant().key(), username, roomID, question, answer, points)
+		}
 		if err != nil {
-			s.Emit("error", err.Error())
+			emitError(s, err)
+			return
+		}
+		s.Emit("notify", Notice{Message: "Your answer has been submitted"})
+		if r.Paced {
+			r, _, err = rooms.Get(cctx, roomID)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			publishPaceGrid(r)
+		}
+		if err := publishStatistic(r, question); err != nil {
+			emitError(s, err)
 			return
 		}
-		roomState[roomID] = state
-		socket.BroadcastToRoom("/", roomID, "getRoomState", roomState[roomID])
 	})
 
-	socket.OnEvent("/", "next", func(s socketio.Conn) {
+	onEvent("/", "showStatistic", func(s socketio.Conn, req QuestionRef) {
 		ctx := s.Context().(*RoomContext)
+		question := req.QuestionID
 		roomID := ctx.RoomID
-		username := ctx.Username
-		err := checkTeacherPermission(username, roomID)
+		r, ok, err := rooms.Get(context.Background(), roomID)
 		if err != nil {
-			s.Emit("error", err.Error())
+			emitError(s, err)
+			return
+		}
+		if !ok || r.SessionID == "" {
+			emitError(s, errNotRunning)
+			return
+		}
+		if !resultsRevealed(r, question) && r.CheckTeacher(ctx.UserID) != nil {
+			emitError(s, errors.New("results of this question are not revealed yet"))
+			return
+		}
+		if err := publishStatistic(r, question); err != nil {
+			emitError(s, err)
 			return
 		}
-		roomState[roomID]++
-		socket.BroadcastToRoom("/", roomID, "getRoomState", roomState[roomID])
 	})
 
-	socket.OnEvent("/", "prev", func(s socketio.Conn) {
+	// submitTextAnswer answers a paragraph question with free text, the answers
+	// are shown as a word cloud.
+	onEvent("/", "submitTextAnswer", func(s socketio.Conn, req TextAnswerRequest) {
 		ctx := s.Context().(*RoomContext)
-		roomID := ctx.RoomID
+		questionID := req.QuestionID
 		username := ctx.Username
-		err := checkTeacherPermission(username, roomID)
+		roomID := ctx.RoomID
+		cctx := context.Background()
+		r, _, err := rooms.Get(cctx, roomID)
 		if err != nil {
-			s.Emit("error", err.Error())
+			emitError(s, err)
 			return
 		}
-		if roomState[roomID] > 1 {
-			roomState[roomID]--
-			socket.BroadcastToRoom("/", roomID, "getRoomState", roomState[roomID])
-		} else {
-			s.Emit("error", "You are at the first question")
+		if r.SessionID == "" {
+			emitError(s, errNotRunning)
+			return
 		}
-	})
-
-	socket.OnEvent("/", "join", func(s socketio.Conn, username, roomID, token string) {
-		fmt.Println(s.ID(), "join room", roomID)
-		if isRoomGroup[roomID] {
-			err := checkUserInGroup(server, roomGroup[roomID], token)
-			if err != nil {
-				s.Emit("error", err.Error())
-				return
-			}
+		question, err := server.QuestionService.DB.GetQuestion(cctx, questionID)
+		if err != nil || question.SlideID != roomID {
+			emitError(s, errors.New("question does not exist"))
+			return
 		}
-		ctx := &RoomContext{
-			Username:  username,
-			RoomID:    roomID,
-			IsTeacher: false,
+		if question.Type != constants.QuestionType_PARAGRAPH {
+			emitError(s, errors.New("this question does not take a written answer"))
+			return
 		}
-		s.SetContext(ctx)
-		exist := checkExistInRoom(username, roomID)
-		if exist {
-			for i, participant := range room[roomID] {
-				if participant.Username == username {
-					if participant.Status == constants.SocketParticipantStatus_ACTIVE {
-						s.Emit("error", "You are already in the room")
-						return
-					}
-					room[roomID][i].Status = constants.SocketParticipantStatus_ACTIVE
-					room[roomID][i].SID = s.ID()
-				}
-			}
-		} else {
-			room[roomID] = append(room[roomID], Participant{
-				IsTeacher: false,
-				Username:  username,
-				Status:    constants.SocketParticipantStatus_ACTIVE,
-				SID:       s.ID(),
-			})
+		text, err := validateTextAnswer(req.Text)
+		if err != nil {
+			emitError(s, err)
+			return
 		}
-		s.Join(roomID)
-	})
-
-	socket.OnEvent("/", "cancelPresentation", func(s socketio.Conn, groupID, token string) {
-		roomID, ok := groupSlidePresent[groupID]
-		if !ok {
-			s.Emit("notify", "Slide does not present, skip cancel")
+		if _, _, err := participantAnswerWindow(r, ctx.participant().key(), questionID, time.Now()); err != nil {
+			emitError(s, err)
 			return
 		}
-		err := checkUserInGroup(server, groupID, token)
-		if err != nil {
-			s.Emit("error", err.Error())
+		if err := submitAnswer(cctx, rooms, roomID, ctx.participant().key(), questionID, text); err != nil {
+			emitError(s, err)
 			return
 		}
-		delete(room, roomID)
-		delete(roomState, roomID)
-		delete(groupSlidePresent, roomGroup[roomID])
-		delete(roomGroup, roomID)
-		socket.BroadcastToRoom("/", roomID, "cancelPresentation", roomID)
-	})
-
-	socket.OnEvent("/", "getSlidePresentation", func(s socketio.Conn, groupID string) {
-		_, ok := groupSlidePresent[groupID]
-		if !ok {
-			s.Emit("error", "Group does not have any slide presentation")
+		if err := server.SlideService.SaveTextAnswer(r.SessionID, ctx.participant().key(), username, roomID, questionID, text); err != nil {
+			emitError(s, err)
 			return
 		}
-		s.Emit("getSlidePresentation", groupSlidePresent[groupID])
+		s.Emit("notify", Notice{Message: "Your answer has been submitted"})
+		if r.Paced {
+			r, _, err = rooms.Get(cctx, roomID)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			publishPaceGrid(r)
+		}
+		if err := publishWordCloud(r, questionID); err != nil {
+			emitError(s, err)
+		}
 	})
 
-	socket.OnEvent("/", "submitAnswer", func(s socketio.Conn, question string, answer string) {
+	onEvent("/", "showWordCloud", func(s socketio.Conn, req QuestionRef) {
 		ctx := s.Context().(*RoomContext)
-		username := ctx.Username
-		roomID := ctx.RoomID
-		fmt.Println("submitAnswer:", username, roomID, answer)
-		err := server.SlideService.SaveAnswerHistory(username, roomID, question, answer)
+		questionID := req.QuestionID
+		r, ok, err := rooms.Get(context.Background(), ctx.RoomID)
 		if err != nil {
-			s.Emit("error", err.Error())
+			emitError(s, err)
 			return
 		}
-		s.Emit("notify", "Your answer has been submitted")
-		count, err := server.SlideService.CountAnswerByQuestionID(question)
-		if err != nil {
-			s.Emit("error", err.Error())
+		if !ok || r.SessionID == "" {
+			emitError(s, errNotRunning)
 			return
 		}
-		// send to all participants
-		socket.BroadcastToRoom("/", roomID, "showStatistic", count)
-		result, err := server.SlideService.ListAnswerHistoryByQuestionID(question)
-		if err != nil {
-			s.Emit("error", err.Error())
+		if !resultsRevealed(r, questionID) && r.CheckTeacher(ctx.UserID) != nil {
+			emitError(s, errors.New("results of this question are not revealed yet"))
 			return
 		}
-		socket.BroadcastToRoom("/", roomID, "resultList", result)
+		if err := publishWordCloud(r, questionID); err != nil {
+			emitError(s, err)
+		}
 	})
 
-	socket.OnEvent("/", "showStatistic", func(s socketio.Conn, question string) {
+	// setWordStemming makes word clouds count the forms of a word as one.
+	onEvent("/", "setWordStemming", func(s socketio.Conn, req Toggle) {
 		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
 		roomID := ctx.RoomID
-		// count answer
-		count, err := server.SlideService.CountAnswerByQuestionID(question)
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
 		if err != nil {
-			s.Emit("error", err.Error())
+			emitError(s, err)
 			return
 		}
-		// send to all participants
-		socket.BroadcastToRoom("/", roomID, "showStatistic", count)
-		result, err := server.SlideService.ListAnswerHistoryByQuestionID(question)
+		_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			r.StemWords = req.Enabled
+			return nil
+		})
 		if err != nil {
-			s.Emit("error", err.Error())
+			emitError(s, err)
 			return
 		}
-		socket.BroadcastToRoom("/", roomID, "resultList", result)
+		s.Emit("wordStemming", req)
 	})
 
-	// socket.OnEvent("/", "saveSlideHistory", func(s socketio.Conn) {
+	// onEvent("/", "saveSlideHistory", func(s socketio.Conn) {
 	// 	ctx := s.Context().(*RoomContext)
 	// 	roomID := ctx.RoomID
 	// 	// save slide history
@@ -317,7 +1182,7 @@ func InitSocketServer(server *Server) *socketio.Server {
 	// 		QuestionResult: question,
 	// 	})
 	// 	if err != nil {
-	// 		s.Emit("error", fmt.Errorf("save slide history failed: %w", err))
+	// 		emitError(s, fmt.Errorf("save slide history failed: %w", err))
 	// 		return
 	// 	}
 	// 	s.Emit("notify", "Your slide history has been saved")
@@ -329,167 +1194,503 @@ func InitSocketServer(server *Server) *socketio.Server {
 
 	socket.OnDisconnect("/", func(s socketio.Conn, reason string) {
 		fmt.Println("closed", reason, s.ID())
-		for id, participants := range room {
-			for i, participant := range participants {
-				if participant.SID == s.ID() {
-					participant.Status = constants.SocketParticipantStatus_LEFT
-					room[id][i] = participant
-				}
-			}
+		guard.limiter.forget(s.ID())
+		closed, err := rooms.Disconnect(context.Background(), s.ID())
+		if err != nil {
+			fmt.Println("disconnect failed:", err)
 		}
-		// check all left
-		for id, participants := range room {
-			allLeft := true
-			for _, participant := range participants {
-				if participant.Status == constants.SocketParticipantStatus_ACTIVE {
-					allLeft = false
-					break
-				}
-			}
-			if allLeft {
-				delete(room, id)
-				delete(roomState, id)
-				delete(groupSlidePresent, roomGroup[id])
-				delete(roomGroup, id)
-			}
+		for _, id := range closed {
+			closeRoom(id)
 		}
 	})
 
 	// chat
-	socket.OnEvent("/", "chat", func(s socketio.Conn, msg string) {
+	chatFilter := newChatFilter(server.SlideService.Config.ChatBlocklist, server.SlideService.Config.ChatFilter)
+
+	// chat sends a message to the room, or a reply to the message parentID,
+	// unless the participant is muted or has to wait in slow mode. Blocked words
+	// are masked or the message rejected.
+	onEvent("/", "chat", func(s socketio.Conn, req ChatRequest) {
 		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
 		roomID := ctx.RoomID
 		username := ctx.Username
-		if err := server.SlideService.SaveChatMsg(roomID, username, msg); err != nil {
-			s.Emit("error", fmt.Errorf("save chat message failed: %w", err))
+		sessionID, err := currentSession(cctx, rooms, roomID)
+		if err != nil {
+			emitError(s, err)
 			return
 		}
-		// send to all participants
-		socket.BroadcastToRoom("/", roomID, "chat", username, msg)
+		msg, err := chatFilter.Filter(req.Msg)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		key := ctx.participant().key()
+		now := time.Now()
+		r, _, err := rooms.Get(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if err := r.allowChat(key, now); err != nil {
+			emitError(s, err)
+			return
+		}
+		saved, err := server.SlideService.SaveChatMsg(sessionID, roomID, username, msg, req.ParentID)
+		if err != nil {
+			emitError(s, fmt.Errorf("save chat message failed: %w", err))
+			return
+		}
+		// the wait for the next message starts only once this one is saved
+		_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			r.recordChat(key, now)
+			return nil
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		// send to all participants, the ID is what hosts delete it by and the
+		// parent ID the thread a reply goes in
+		broadcaster.BroadcastToRoom("/", roomID, "chat", ChatMessage{
+			Username: username,
+			Msg:      msg,
+			MsgID:    saved.ID,
+			ParentID: saved.ParentID,
+		})
+	})
+
+	// deleteChat hides a message from everyone's chat.
+	onEvent("/", "deleteChat", func(s socketio.Conn, req MsgRef) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		sessionID, err := currentSession(cctx, rooms, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if err := server.SlideService.DeleteChatMsg(sessionID, req.MsgID); err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "chatDeleted", req)
+	})
+
+	// muteParticipant stops or lets a participant send chat messages, for the
+	// rest of the session even when it reconnects.
+	onEvent("/", "muteParticipant", func(s socketio.Conn, req MuteRequest) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		var p Participant
+		_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			var err error
+			p, err = r.setMuted(req.Participant, req.Muted)
+			return err
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		notice := ParticipantMuted{Participant: p.info(), Muted: req.Muted}
+		if p.SID != "" {
+			broadcaster.BroadcastToRoom("/", connRoom(p.SID), "muted", notice)
+		}
+		broadcaster.BroadcastToRoom("/", hostRoom(roomID), "participantMuted", notice)
+	})
+
+	// setSlowMode makes participants wait seconds between chat messages, zero
+	// turns it off.
+	onEvent("/", "setSlowMode", func(s socketio.Conn, req SlowMode) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		r, err := rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			return r.setSlowMode(req.Seconds)
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", roomID, "slowMode", SlowMode{Seconds: r.SlowMode})
+	})
+
+	// getChatHistory sends a page of messages older than the cursor, the first
+	// page without one. Limit defaults to 50.
+	onEvent("/", "getChatHistory", func(s socketio.Conn, req ChatHistoryRequest) {
+		ctx := s.Context().(*RoomContext)
+		roomID := ctx.RoomID
+		sessionID, err := currentSession(context.Background(), rooms, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		page, err := server.SlideService.GetChatPage(sessionID, ctx.participant().key(), req.Cursor, req.Limit)
+		if err != nil {
+			emitError(s, fmt.Errorf("get chat history failed: %w", err))
+			return
+		}
+		s.Emit("chatHistory", page)
+	})
+
+	onEvent("/", "getChatReplies", func(s socketio.Conn, req ChatRepliesRequest) {
+		ctx := s.Context().(*RoomContext)
+		roomID := ctx.RoomID
+		sessionID, err := currentSession(context.Background(), rooms, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		replies, err := server.SlideService.GetChatReplies(sessionID, ctx.participant().key(), req.ParentID)
+		if err != nil {
+			emitError(s, fmt.Errorf("get chat replies failed: %w", err))
+			return
+		}
+		s.Emit("chatReplies", ChatReplies{ParentID: req.ParentID, Replies: replies})
 	})
 
-	socket.OnEvent("/", "getChatHistory", func(s socketio.Conn) {
+	// reactChat adds an emoji reaction to a message or takes it back, the room
+	// gets the new counts of the message.
+	onEvent("/", "reactChat", func(s socketio.Conn, req ReactChatRequest) {
 		ctx := s.Context().(*RoomContext)
 		roomID := ctx.RoomID
-		chatMsgs, err := server.SlideService.GetChatMsgs(roomID)
+		sessionID, err := currentSession(context.Background(), rooms, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		reactions, reacted, err := server.SlideService.ToggleChatReaction(sessionID, req.MsgID, ctx.participant().key(), req.Emoji)
 		if err != nil {
-			s.Emit("error", fmt.Errorf("get chat history failed: %w", err))
+			emitError(s, err)
+			return
+		}
+		s.Emit("chatReacted", ChatReacted{MsgID: req.MsgID, Emoji: req.Emoji, Reacted: reacted})
+		broadcaster.BroadcastToRoom("/", roomID, "chatReaction", reactions)
+	})
+
+	// react sends a quick reaction to the presenter screen, they go out in
+	// batches so a big room does not flood the broadcaster.
+	onEvent("/", "react", func(s socketio.Conn, req ReactRequest) {
+		ctx := s.Context().(*RoomContext)
+		if ctx.RoomID == "" {
+			emitError(s, errNotInRoom)
+			return
+		}
+		if err := validateLiveReaction(req.Emoji); err != nil {
+			emitError(s, err)
 			return
 		}
-		s.Emit("chatHistory", chatMsgs)
+		reactionBatch.add(ctx.RoomID, req.Emoji)
 	})
 
 	// user question
-	socket.OnEvent("/", "postQuestion", func(s socketio.Conn, msg string) {
+	// postQuestion asks the hosts a question, anonymous hides who asked it. In
+	// moderated rooms it waits for a host to approve it.
+	onEvent("/", "postQuestion", func(s socketio.Conn, req AskQuestionRequest) {
 		ctx := s.Context().(*RoomContext)
 		cctx := context.Background()
 		roomID := ctx.RoomID
 		username := ctx.Username
+		r, ok, err := rooms.Get(cctx, roomID)
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if !ok || r.SessionID == "" {
+			emitError(s, errNotRunning)
+			return
+		}
 		question, err := server.UserQuestionService.PostQuestion(cctx, PostQuestionRequest{
-			SlideID:  roomID,
-			Username: username,
-			Content:  msg,
+			SessionID: r.SessionID,
+			SlideID:   roomID,
+			Username:  username,
+			Content:   req.Msg,
+			Anonymous: req.Anonymous,
+			Moderated: r.ModerateQuestions,
 		})
 		if err != nil {
-			s.Emit("error", fmt.Errorf("post question failed: %w", err).Error())
+			emitError(s, fmt.Errorf("post question failed: %w", err))
+			return
+		}
+		if question.Status == constants.UserQuestionStatus_PENDING {
+			s.Emit("questionPending", question)
+			broadcaster.BroadcastToRoom("/", hostRoom(roomID), "pendingQuestion", question)
 			return
 		}
 		// send to all participants
-		socket.BroadcastToRoom("/", roomID, "postQuestion", question)
+		broadcaster.BroadcastToRoom("/", roomID, "postQuestion", publicUserQuestion(question))
 	})
 
-	socket.OnEvent("/", "listUserQuestion", func(s socketio.Conn) {
+	// listUserQuestion lists the approved questions sorted by top, newest or
+	// answered and marks the ones the caller voted for.
+	onEvent("/", "listUserQuestion", func(s socketio.Conn, req ListUserQuestionRequest) {
 		ctx := s.Context().(*RoomContext)
 		cctx := context.Background()
-		roomID := ctx.RoomID
-		questions, err := server.UserQuestionService.ListQuestionBySlideID(cctx, roomID)
+		r, voter, err := questionVoter(cctx, rooms, ctx.RoomID, ctx.participant().key())
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		questions, err := server.UserQuestionService.ListQuestionForVoter(cctx, r.SessionID, voter, req.Sort)
 		if err != nil {
-			s.Emit("error", fmt.Errorf("list user question failed: %w", err).Error())
+			emitError(s, fmt.Errorf("list user question failed: %w", err))
 			return
 		}
-		s.Emit("listUserQuestion", questions)
+		for i := range questions {
+			questions[i].Pinned = questions[i].QuestionID == r.PinnedQuestion
+		}
+		s.Emit("listUserQuestion", UserQuestionList{Questions: questions})
 	})
-	socket.OnEvent("/", "upvoteQuestion", func(s socketio.Conn, questionID string) {
+
+	// upvoteQuestion votes for a question or takes the vote back, the caller is
+	// told which it was.
+	onEvent("/", "upvoteQuestion", func(s socketio.Conn, req QuestionRef) {
 		ctx := s.Context().(*RoomContext)
 		cctx := context.Background()
 		roomID := ctx.RoomID
-		question, err := server.UserQuestionService.UpvoteQuestion(cctx, questionID)
+		r, voter, err := questionVoter(cctx, rooms, roomID, ctx.participant().key())
 		if err != nil {
-			s.Emit("error", fmt.Errorf("upvote question failed: %w", err).Error())
+			emitError(s, err)
 			return
 		}
+		question, voted, err := server.UserQuestionService.ToggleVote(cctx, r.SessionID, req.QuestionID, voter)
+		if err != nil {
+			emitError(s, fmt.Errorf("upvote question failed: %w", err))
+			return
+		}
+		question = publicUserQuestion(question)
+		s.Emit("questionVoted", UserQuestionItem{
+			UserQuestion: question,
+			Voted:        voted,
+			Pinned:       question.QuestionID == r.PinnedQuestion,
+		})
 		// send to all participants
-		socket.BroadcastToRoom("/", roomID, "upvoteQuestion", question)
+		broadcaster.BroadcastToRoom("/", roomID, "upvoteQuestion", question)
 	})
-	socket.OnEvent("/", "toggleUserQuestionAnswered", func(s socketio.Conn, questionID string) {
+
+	// hostQuestionSession returns the session of the room when the caller hosts it.
+	hostQuestionSession := func(s socketio.Conn) (LiveRoom, bool) {
 		ctx := s.Context().(*RoomContext)
 		cctx := context.Background()
-		roomID := ctx.RoomID
-		isTeacher := ctx.IsTeacher
-		if !isTeacher {
-			s.Emit("error", "only owner and co-owner can toggle user question answered")
+		err := checkTeacher(cctx, rooms, ctx.RoomID, ctx.UserID)
+		if err != nil {
+			emitError(s, err)
+			return LiveRoom{}, false
+		}
+		r, _, err := rooms.Get(cctx, ctx.RoomID)
+		if err != nil {
+			emitError(s, err)
+			return LiveRoom{}, false
+		}
+		if r.SessionID == "" {
+			emitError(s, errNotRunning)
+			return LiveRoom{}, false
+		}
+		return r, true
+	}
+
+	onEvent("/", "toggleUserQuestionAnswered", func(s socketio.Conn, req QuestionRef) {
+		r, ok := hostQuestionSession(s)
+		if !ok {
 			return
 		}
-		question, err := server.UserQuestionService.ToggleUserQuestionAnswered(cctx, questionID)
+		question, err := server.UserQuestionService.ToggleUserQuestionAnswered(context.Background(), r.SessionID, req.QuestionID)
 		if err != nil {
-			s.Emit("error", fmt.Errorf("toggle user question answered failed: %w", err).Error())
+			emitError(s, fmt.Errorf("toggle user question answered failed: %w", err))
 			return
 		}
 		// send to all participants
-		socket.BroadcastToRoom("/", roomID, "toggleUserQuestionAnswered", question)
+		broadcaster.BroadcastToRoom("/", r.ID, "toggleUserQuestionAnswered", publicUserQuestion(question))
 	})
 
-	// server notification
-	socket.OnEvent("/notification", "join", func(s socketio.Conn, token string) {
-		res, err := server.AuthService.JWT.ValidateToken(token)
+	// setQuestionModeration turns the approval queue for questions of the
+	// audience on or off.
+	onEvent("/", "setQuestionModeration", func(s socketio.Conn, req Toggle) {
+		ctx := s.Context().(*RoomContext)
+		cctx := context.Background()
+		roomID := ctx.RoomID
+		err := checkTeacher(cctx, rooms, roomID, ctx.UserID)
 		if err != nil {
-			s.Emit("error", fmt.Errorf("invalid token: %w", err).Error())
+			emitError(s, err)
 			return
 		}
-		groups, err := server.AuthService.DB.GetGroupByUser(context.Background(), res.UserID)
+		_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			r.ModerateQuestions = req.Enabled
+			return nil
+		})
 		if err != nil {
-			s.Emit("error", fmt.Errorf("get group by user failed: %w", err).Error())
+			emitError(s, err)
 			return
 		}
-		for _, group := range groups {
-			s.Join(group.GroupID)
+		broadcaster.BroadcastToRoom("/", roomID, "questionModeration", req)
+	})
+
+	onEvent("/", "listPendingQuestions", func(s socketio.Conn) {
+		r, ok := hostQuestionSession(s)
+		if !ok {
+			return
+		}
+		questions, err := server.UserQuestionService.ListPendingQuestions(context.Background(), r.SessionID)
+		if err != nil {
+			emitError(s, fmt.Errorf("list pending questions failed: %w", err))
+			return
 		}
+		s.Emit("listPendingQuestions", PendingQuestions{Questions: questions})
 	})
 
-	return socket
-}
+	// moderateQuestion approves or rejects a question, approved questions are
+	// sent to the room as if they were just posted. A rejected question is taken
+	// down from the presenter screen.
+	moderateQuestion := func(s socketio.Conn, questionID, status string) {
+		r, ok := hostQuestionSession(s)
+		if !ok {
+			return
+		}
+		cctx := context.Background()
+		question, err := server.UserQuestionService.ModerateQuestion(cctx, r.SessionID, questionID, status)
+		if err != nil {
+			emitError(s, fmt.Errorf("moderate question failed: %w", err))
+			return
+		}
+		broadcaster.BroadcastToRoom("/", hostRoom(r.ID), "questionModerated", question)
+		if status == constants.UserQuestionStatus_APPROVED {
+			broadcaster.BroadcastToRoom("/", r.ID, "postQuestion", publicUserQuestion(question))
+			return
+		}
+		broadcaster.BroadcastToRoom("/", r.ID, "questionRemoved", QuestionRef{QuestionID: question.QuestionID})
 
-func checkExistInRoom(username, roomID string) bool {
-	for _, participant := range room[roomID] {
-		if participant.Username == username {
-			return true
+		unpinned := false
+		_, err = rooms.Update(cctx, r.ID, func(r *LiveRoom) error {
+			unpinned = r.PinnedQuestion == question.QuestionID
+			if unpinned {
+				r.PinnedQuestion = ""
+			}
+			return nil
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		if unpinned {
+			broadcaster.BroadcastToRoom("/", r.ID, "pinnedQuestion", PinnedQuestion{})
 		}
 	}
-	return false
-}
 
-func checkTeacherPermission(username, roomID string) error {
-	for _, participant := range room[roomID] {
-		if participant.Username == username {
-			if participant.IsTeacher {
-				return nil
+	onEvent("/", "approveQuestion", func(s socketio.Conn, req QuestionRef) {
+		moderateQuestion(s, req.QuestionID, constants.UserQuestionStatus_APPROVED)
+	})
+
+	onEvent("/", "rejectQuestion", func(s socketio.Conn, req QuestionRef) {
+		moderateQuestion(s, req.QuestionID, constants.UserQuestionStatus_REJECTED)
+	})
+
+	onEvent("/", "editQuestion", func(s socketio.Conn, req EditQuestionRequest) {
+		r, ok := hostQuestionSession(s)
+		if !ok {
+			return
+		}
+		question, err := server.UserQuestionService.EditQuestion(context.Background(), r.SessionID, req.QuestionID, req.Content)
+		if err != nil {
+			emitError(s, fmt.Errorf("edit question failed: %w", err))
+			return
+		}
+		broadcaster.BroadcastToRoom("/", hostRoom(r.ID), "questionEdited", question)
+		if question.Status == constants.UserQuestionStatus_APPROVED {
+			broadcaster.BroadcastToRoom("/", r.ID, "questionEdited", publicUserQuestion(question))
+		}
+	})
+
+	// pinQuestion puts an approved question on the presenter screen, an empty
+	// question ID takes it down.
+	onEvent("/", "pinQuestion", func(s socketio.Conn, req QuestionRef) {
+		questionID := req.QuestionID
+		r, ok := hostQuestionSession(s)
+		if !ok {
+			return
+		}
+		cctx := context.Background()
+		var pinned *entities.UserQuestion
+		if questionID != "" {
+			question, err := server.UserQuestionService.GetSessionQuestion(cctx, r.SessionID, questionID)
+			if err != nil {
+				emitError(s, err)
+				return
+			}
+			if question.Status != constants.UserQuestionStatus_APPROVED {
+				emitError(s, errors.New("only approved questions can be pinned"))
+				return
 			}
-			return fmt.Errorf("you are not a teacher in the room")
+			question = publicUserQuestion(question)
+			pinned = &question
 		}
-	}
-	return fmt.Errorf("you are not in the room")
+		_, err := rooms.Update(cctx, r.ID, func(r *LiveRoom) error {
+			r.PinnedQuestion = questionID
+			return nil
+		})
+		if err != nil {
+			emitError(s, err)
+			return
+		}
+		broadcaster.BroadcastToRoom("/", r.ID, "pinnedQuestion", PinnedQuestion{Question: pinned})
+	})
+
+	// server notification
+	onEvent("/notification", "join", func(s socketio.Conn, req TokenRequest) {
+		res, err := server.AuthService.JWT.ValidateToken(req.Token)
+		if err != nil {
+			emitError(s, fmt.Errorf("invalid token: %w", err))
+			return
+		}
+		groups, err := server.AuthService.DB.GetGroupByUser(context.Background(), res.UserID)
+		if err != nil {
+			emitError(s, fmt.Errorf("get group by user failed: %w", err))
+			return
+		}
+		for _, group := range groups {
+			s.Join(group.GroupID)
+		}
+	})
+
+	return socket
 }
 
-func checkUserInGroup(server *Server, groupID, token string) error {
-	// check token
-	res, err := server.AuthService.JWT.ValidateToken(token)
+// questionVoter returns the room and who votes for its audience questions as
+// the participant. Every participant has one vote per question.
+func questionVoter(ctx context.Context, rooms SessionStore, roomID, key string) (LiveRoom, string, error) {
+	r, ok, err := rooms.Get(ctx, roomID)
 	if err != nil {
-		return fmt.Errorf("invalid token: %w", err)
+		return LiveRoom{}, "", err
+	}
+	if !ok || r.SessionID == "" {
+		return LiveRoom{}, "", errNotRunning
 	}
+	p := r.participant(key)
+	if p == nil {
+		return LiveRoom{}, "", errNotInRoom
+	}
+	return r, p.key(), nil
+}
 
+func checkUserInGroup(server *Server, groupID, userID string) error {
 	isUserInGroup, err := server.GroupService.DB.CheckUserInGroup(context.Background(), repositories.CheckUserInGroupParams{
 		GroupID: groupID,
-		UserID:  res.UserID,
+		UserID:  userID,
 	})
 	if err != nil {
 		return fmt.Errorf("check user in group failed: %w", err)
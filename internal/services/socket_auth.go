@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+// participantTokenExpiredTime is in hours, long enough for any presentation.
+const participantTokenExpiredTime = 24
+
+var errSocketUnauthenticated = fmt.Errorf("you need to log in to do this action")
+
+// handshakeToken returns the access token sent with the socket handshake, as
+// the token query parameter, the authorization header or the access cookie.
+func handshakeToken(s socketio.Conn) string {
+	u := s.URL()
+	if token := u.Query().Get("token"); token != "" {
+		return token
+	}
+
+	header := s.RemoteHeader()
+	if token := strings.TrimPrefix(header.Get("authorization"), "Bearer "); token != header.Get("authorization") {
+		return token
+	}
+
+	req := http.Request{Header: header}
+	if cookie, err := req.Cookie(constants.Cookies_ACCESS_TOKEN); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// socketContext returns the context of the connection, set when it connected.
+func socketContext(s socketio.Conn) *RoomContext {
+	ctx, ok := s.Context().(*RoomContext)
+	if !ok {
+		ctx = &RoomContext{}
+		s.SetContext(ctx)
+	}
+	return ctx
+}
+
+// participant returns who the connection is in its room, to look it up by key.
+func (ctx *RoomContext) participant() Participant {
+	return Participant{UserID: ctx.UserID, ID: ctx.GuestID, Username: ctx.Username}
+}
+
+// authenticate verifies the token with the same JWT as the HTTP API and sets
+// the user of the connection. The user is told apart by its ID, the name is
+// only for display. A connection that is already authenticated keeps its user.
+func (ctx *RoomContext) authenticate(server *Server, token string) error {
+	if ctx.UserID != "" || token == "" {
+		return nil
+	}
+
+	claims, err := server.AuthService.JWT.ValidateToken(token)
+	if err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	user, err := server.AuthService.DB.GetUser(context.Background(), claims.UserID)
+	if err != nil {
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	ctx.UserID = user.UserID
+	ctx.Username = user.Name
+	if ctx.Username == "" {
+		ctx.Username = user.Email
+	}
+	return nil
+}
+
+// identify authenticates the connection with the token of its first event when
+// it was not authenticated at handshake.
+func identify(server *Server, s socketio.Conn, token string) (*RoomContext, error) {
+	ctx := socketContext(s)
+	if err := ctx.authenticate(server, token); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// guestParticipant returns the guest a participant token was issued to, or a new
+// guest with the nickname when there is no token. The token is the only way to
+// take a guest's place, and its answers, again, and only in the session it was
+// issued for: a later presentation of the slide starts afresh.
+func guestParticipant(server *Server, sessionID, nickname, participantToken string) (Participant, bool, error) {
+	if participantToken != "" {
+		claims, err := server.AuthService.JWT.ValidateParticipantToken(participantToken)
+		if err != nil {
+			return Participant{}, false, fmt.Errorf("invalid participant token: %w", err)
+		}
+		if claims.SessionID != sessionID {
+			return Participant{}, false, fmt.Errorf("participant token is for another session")
+		}
+		return Participant{
+			ID:       claims.ParticipantID,
+			Username: claims.Username,
+		}, false, nil
+	}
+
+	nickname, err := validateNickname(nickname)
+	if err != nil {
+		return Participant{}, false, err
+	}
+	return Participant{
+		ID:       uuid.NewString(),
+		Username: nickname,
+	}, true, nil
+}
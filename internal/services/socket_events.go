@@ -0,0 +1,180 @@
+package services
+
+// Every socket event carries at most one payload, a struct with snake_case
+// JSON fields like the entities and the REST API. These are the payloads of
+// the events clients send, and the small ones shared by events of both ways.
+// The payloads of the other events sit with the state they describe.
+
+// HostRequest starts or rejoins the room of a slide, the host is the user of
+// the token. A new room can start with its lobby on and a participant cap,
+// zero for the configured default.
+type HostRequest struct {
+	RoomID          string `json:"room_id"`
+	IsGroup         bool   `json:"is_group"`
+	GroupID         string `json:"group_id"`
+	Token           string `json:"token"`
+	Lobby           bool   `json:"lobby"`
+	MaxParticipants int    `json:"max_participants"`
+}
+
+// JoinRequest joins a room by its ID or game PIN. Username is the nickname of
+// a new guest, logged in users join with their own name and guests rejoin
+// with the participant token they were given. Team is the team picked when
+// participants pick their own.
+type JoinRequest struct {
+	Username         string `json:"username"`
+	RoomID           string `json:"room_id"`
+	Token            string `json:"token"`
+	ParticipantToken string `json:"participant_token"`
+	Team             string `json:"team"`
+}
+
+// KickRequest removes a participant, given by its key, with Ban it cannot
+// join again for the session.
+type KickRequest struct {
+	Participant string `json:"participant"`
+	Ban         bool   `json:"ban"`
+}
+
+type SetRoomStateRequest struct {
+	State int `json:"state"`
+}
+
+// TeamModeRequest plays in teams, Mode is auto or pick, or empty to stop.
+type TeamModeRequest struct {
+	Mode  string   `json:"mode"`
+	Teams []string `json:"teams"`
+}
+
+type PickTeamRequest struct {
+	Team string `json:"team"`
+}
+
+type MaxParticipantsRequest struct {
+	MaxParticipants int `json:"max_participants"`
+}
+
+type CancelPresentationRequest struct {
+	GroupID string `json:"group_id"`
+	Token   string `json:"token"`
+}
+
+// SubmitAnswerRequest answers a question with an answer ID, the answer IDs
+// picked in order for multi-select and ranking questions or the value of a
+// scale question.
+type SubmitAnswerRequest struct {
+	QuestionID string   `json:"question_id"`
+	AnswerID   string   `json:"answer_id"`
+	Selection  []string `json:"selection"`
+	Value      int      `json:"value"`
+}
+
+type TextAnswerRequest struct {
+	QuestionID string `json:"question_id"`
+	Text       string `json:"text"`
+}
+
+// ChatRequest sends a message, or a reply to the message ParentID.
+type ChatRequest struct {
+	Msg      string `json:"msg"`
+	ParentID string `json:"parent_id"`
+}
+
+// MuteRequest stops or lets a participant, given by its key, chat.
+type MuteRequest struct {
+	Participant string `json:"participant"`
+	Muted       bool   `json:"muted"`
+}
+
+// ChatHistoryRequest asks for the page of messages before Cursor, Limit
+// defaults to 50.
+type ChatHistoryRequest struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type ChatRepliesRequest struct {
+	ParentID string `json:"parent_id"`
+}
+
+type ReactChatRequest struct {
+	MsgID string `json:"msg_id"`
+	Emoji string `json:"emoji"`
+}
+
+type ReactRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// AskQuestionRequest asks the hosts a question, Anonymous hides who asked.
+type AskQuestionRequest struct {
+	Msg       string `json:"msg"`
+	Anonymous bool   `json:"anonymous"`
+}
+
+// ListUserQuestionRequest lists the questions of the audience, Sort is top,
+// newest or answered.
+type ListUserQuestionRequest struct {
+	Sort string `json:"sort"`
+}
+
+type EditQuestionRequest struct {
+	QuestionID string `json:"question_id"`
+	Content    string `json:"content"`
+}
+
+type TokenRequest struct {
+	Token string `json:"token"`
+}
+
+// Toggle turns a setting of the room on or off, and tells the room it did.
+type Toggle struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SlowMode is how many seconds participants wait between chat messages, zero
+// when it is off.
+type SlowMode struct {
+	Seconds int `json:"seconds"`
+}
+
+type RoomList struct {
+	RoomIDs []string `json:"room_ids"`
+}
+
+// GamePin is the short code participants join the room with.
+type GamePin struct {
+	Pin string `json:"pin"`
+}
+
+// ParticipantToken lets a guest rejoin as the same participant.
+type ParticipantToken struct {
+	Token string `json:"token"`
+}
+
+type RoomRef struct {
+	RoomID string `json:"room_id"`
+}
+
+type GroupRef struct {
+	GroupID string `json:"group_id"`
+}
+
+type QuestionRef struct {
+	QuestionID string `json:"question_id"`
+}
+
+type MsgRef struct {
+	MsgID string `json:"msg_id"`
+}
+
+// ParticipantRef is a participant given by its key, user/<UserID> for logged
+// in users and guest/<ID> for guests.
+type ParticipantRef struct {
+	Participant string `json:"participant"`
+}
+
+// Notice is a message for the user.
+type Notice struct {
+	Message string `json:"message"`
+}
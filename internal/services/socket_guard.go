@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+// maxListField is how many items a list field of a payload can have.
+const maxListField = 50
+
+// socketID matches the IDs clients send back, the IDs are UUIDs.
+var socketID = regexp.MustCompile(`^[0-9A-Za-z-]{1,64}$`)
+
+// eventRule is the limit of an event for each connection. A connection can
+// send Burst events at once and then one every Refill. String fields of the
+// payload, and the items of list fields, are at most MaxSize bytes, the
+// fields named in IDFields are IDs when they are set.
+type eventRule struct {
+	Burst    int
+	Refill   time.Duration
+	MaxSize  int
+	IDFields []string
+}
+
+var defaultEventRule = eventRule{Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024}
+
+// eventRules are the events that differ from the default, mostly the ones the
+// audience can send that write to the database.
+var eventRules = map[string]eventRule{
+	"host":                       {Burst: 5, Refill: time.Second, MaxSize: 4096, IDFields: []string{"room_id", "group_id"}},
+	"join":                       {Burst: 5, Refill: time.Second, MaxSize: 4096, IDFields: []string{"room_id"}},
+	"cancelPresentation":         {Burst: 5, Refill: time.Second, MaxSize: 4096, IDFields: []string{"group_id"}},
+	"getSlidePresentation":       {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"group_id"}},
+	"submitAnswer":               {Burst: 5, Refill: time.Second, MaxSize: 1024, IDFields: []string{"question_id", "answer_id", "selection"}},
+	"showStatistic":              {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"question_id"}},
+	"submitTextAnswer":           {Burst: 5, Refill: time.Second, MaxSize: 2048, IDFields: []string{"question_id"}},
+	"showWordCloud":              {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"question_id"}},
+	"chat":                       {Burst: 5, Refill: time.Second, MaxSize: 2048, IDFields: []string{"parent_id"}},
+	"deleteChat":                 {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"msg_id"}},
+	"getChatReplies":             {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"parent_id"}},
+	"reactChat":                  {Burst: 10, Refill: 500 * time.Millisecond, MaxSize: 1024, IDFields: []string{"msg_id"}},
+	"react":                      {Burst: 5, Refill: 500 * time.Millisecond, MaxSize: 1024},
+	"postQuestion":               {Burst: 3, Refill: 10 * time.Second, MaxSize: 2048},
+	"upvoteQuestion":             {Burst: 10, Refill: 500 * time.Millisecond, MaxSize: 1024, IDFields: []string{"question_id"}},
+	"toggleUserQuestionAnswered": {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"question_id"}},
+	"approveQuestion":            {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"question_id"}},
+	"rejectQuestion":             {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"question_id"}},
+	"editQuestion":               {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 2048, IDFields: []string{"question_id"}},
+	"pinQuestion":                {Burst: 20, Refill: 100 * time.Millisecond, MaxSize: 1024, IDFields: []string{"question_id"}},
+}
+
+// SocketError is emitted as eventError when an event is turned down before
+// its handler runs. RetryAfter is in milliseconds.
+type SocketError struct {
+	Code       string `json:"code"`
+	Event      string `json:"event"`
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+}
+
+// rateLimiter keeps a token bucket for each event of each connection,
+// connections are bound to the instance that limits them.
+type rateLimiter struct {
+	lock sync.Mutex
+	// [Socket ID] -> [event] -> bucket
+	buckets map[string]map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]map[string]*tokenBucket)}
+}
+
+// allow takes a token from the bucket of the event if it has one, or returns
+// how long until it will.
+func (l *rateLimiter) allow(sid, event string, rule eventRule, now time.Time) (bool, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.buckets[sid] == nil {
+		l.buckets[sid] = make(map[string]*tokenBucket)
+	}
+	b, ok := l.buckets[sid][event]
+	if !ok {
+		b = &tokenBucket{tokens: float64(rule.Burst), last: now}
+		l.buckets[sid][event] = b
+	}
+	b.tokens += float64(now.Sub(b.last)) / float64(rule.Refill)
+	if b.tokens > float64(rule.Burst) {
+		b.tokens = float64(rule.Burst)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(rule.Refill))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// forget drops the buckets of a connection that has closed.
+func (l *rateLimiter) forget(sid string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.buckets, sid)
+}
+
+// eventGuard checks the rate and the payload of an event before its handler
+// runs.
+type eventGuard struct {
+	limiter *rateLimiter
+}
+
+func newEventGuard() *eventGuard {
+	return &eventGuard{limiter: newRateLimiter()}
+}
+
+// wrap returns a handler of f that checks its event first and acknowledges it
+// with an Ack. A turned down event is not handled, the connection gets an
+// eventError.
+func (g *eventGuard) wrap(event string, f interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+	rule, ok := eventRules[event]
+	if !ok {
+		rule = defaultEventRule
+	}
+
+	in := make([]reflect.Type, ft.NumIn())
+	for i := range in {
+		in[i] = ft.In(i)
+	}
+	acked := reflect.FuncOf(in, []reflect.Type{reflect.TypeOf(Ack{})}, false)
+	return reflect.MakeFunc(acked, func(args []reflect.Value) []reflect.Value {
+		s := args[0].Interface().(socketio.Conn)
+		var payload reflect.Value
+		if len(args) > 1 {
+			payload = args[1]
+		}
+		if err := g.check(s.ID(), event, rule, payload, time.Now()); err != nil {
+			s.Emit("eventError", *err)
+			return []reflect.Value{reflect.ValueOf(Ack{Code: err.Code, Message: err.Message})}
+		}
+		c := newAckConn(s, event)
+		args[0] = reflect.ValueOf(c).Convert(ft.In(0))
+		fv.Call(args)
+		return []reflect.Value{reflect.ValueOf(c.ack)}
+	}).Interface()
+}
+
+func (g *eventGuard) check(sid, event string, rule eventRule, payload reflect.Value, now time.Time) *SocketError {
+	if err := checkEventPayload(rule, payload); err != nil {
+		return &SocketError{
+			Code:    constants.SocketError_INVALID_ARGUMENT,
+			Event:   event,
+			Message: err.Error(),
+		}
+	}
+	if ok, wait := g.limiter.allow(sid, event, rule, now); !ok {
+		return &SocketError{
+			Code:       constants.SocketError_RATE_LIMITED,
+			Event:      event,
+			Message:    "you are sending this too fast",
+			RetryAfter: int(wait.Round(time.Millisecond) / time.Millisecond),
+		}
+	}
+	return nil
+}
+
+// checkEventPayload checks the text fields of a payload, events without one
+// pass an invalid value.
+func checkEventPayload(rule eventRule, payload reflect.Value) error {
+	if !payload.IsValid() || payload.Kind() != reflect.Struct {
+		return nil
+	}
+	ids := make(map[string]bool, len(rule.IDFields))
+	for _, name := range rule.IDFields {
+		ids[name] = true
+	}
+
+	t := payload.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _ := jsonName(t.Field(i))
+		values, ok := stringValues(payload.Field(i))
+		if !ok {
+			continue
+		}
+		if len(values) > maxListField {
+			return fmt.Errorf("%s has more than %d items", name, maxListField)
+		}
+		for _, value := range values {
+			if len(value) > rule.MaxSize {
+				return fmt.Errorf("%s is longer than %d bytes", name, rule.MaxSize)
+			}
+			if !utf8.ValidString(value) {
+				return fmt.Errorf("%s is not valid text", name)
+			}
+			if ids[name] && value != "" && !socketID.MatchString(value) {
+				return fmt.Errorf("%s is not a valid ID", name)
+			}
+		}
+	}
+	return nil
+}
+
+// stringValues returns the text of a string or string list field.
+func stringValues(v reflect.Value) ([]string, bool) {
+	switch {
+	case v.Kind() == reflect.String:
+		return []string{v.String()}, true
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).String()
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter()
+	rule := eventRule{Burst: 2, Refill: time.Second}
+	now := time.Now()
+
+	for i := 0; i < rule.Burst; i++ {
+		ok, _ := l.allow("a", "chat", rule, now)
+		require.True(t, ok)
+	}
+	ok, wait := l.allow("a", "chat", rule, now)
+	require.False(t, ok)
+	require.Equal(t, time.Second, wait)
+	ok, wait = l.allow("a", "chat", rule, now.Add(400*time.Millisecond))
+	require.False(t, ok)
+	require.Equal(t, 600*time.Millisecond, wait)
+
+	// other events and other connections have their own buckets
+	ok, _ = l.allow("a", "react", rule, now)
+	require.True(t, ok)
+	ok, _ = l.allow("b", "chat", rule, now)
+	require.True(t, ok)
+
+	ok, _ = l.allow("a", "chat", rule, now.Add(time.Second))
+	require.True(t, ok)
+	l.forget("a")
+	ok, _ = l.allow("a", "chat", rule, now.Add(time.Second))
+	require.True(t, ok)
+}
+
+func TestCheckEventPayload(t *testing.T) {
+	rule := eventRule{Burst: 1, Refill: time.Second, MaxSize: 8, IDFields: []string{"question_id", "selection"}}
+	payload := func(question, text string, selection ...string) reflect.Value {
+		return reflect.ValueOf(SubmitAnswerRequest{QuestionID: question, AnswerID: text, Selection: selection, Value: 3})
+	}
+
+	require.NoError(t, checkEventPayload(rule, payload("a-1", "hi", "b", "")))
+	require.NoError(t, checkEventPayload(rule, payload("", "hi")))
+	require.NoError(t, checkEventPayload(rule, reflect.Value{}))
+	require.EqualError(t, checkEventPayload(rule, payload("a-1", "far too long")), "answer_id is longer than 8 bytes")
+	require.EqualError(t, checkEventPayload(rule, payload("a-1", "\xff")), "answer_id is not valid text")
+	require.EqualError(t, checkEventPayload(rule, payload("a 1", "hi")), "question_id is not a valid ID")
+	require.EqualError(t, checkEventPayload(rule, payload("a-1", "hi", "b", "c/d")), "selection is not a valid ID")
+	require.Error(t, checkEventPayload(rule, payload("a-1", "hi", strings.Split(strings.Repeat("x,", maxListField), ",")...)))
+}
+
+func TestEventGuard(t *testing.T) {
+	g := newEventGuard()
+	rule := eventRule{Burst: 1, Refill: time.Second, MaxSize: 8}
+	now := time.Now()
+	chat := func(msg string) reflect.Value {
+		return reflect.ValueOf(ChatRequest{Msg: msg})
+	}
+
+	require.Nil(t, g.check("a", "chat", rule, chat("hi"), now))
+	err := g.check("a", "chat", rule, chat("hi"), now)
+	require.Equal(t, &SocketError{
+		Code:       constants.SocketError_RATE_LIMITED,
+		Event:      "chat",
+		Message:    "you are sending this too fast",
+		RetryAfter: 1000,
+	}, err)
+
+	err = g.check("b", "chat", rule, chat("far too long"), now)
+	require.Equal(t, constants.SocketError_INVALID_ARGUMENT, err.Code)
+	// invalid events do not use up the limit
+	require.Nil(t, g.check("b", "chat", rule, chat("hi"), now))
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+const minTeams = 2
+
+type TeamLeaderboardEntry struct {
+	Rank  int    `json:"rank"`
+	Team  string `json:"team"`
+	Score int    `json:"score"`
+}
+
+type TeamLeaderboard struct {
+	Entries []TeamLeaderboardEntry `json:"entries"`
+}
+
+// TeamState is how the room is split into teams.
+type TeamState struct {
+	Mode  string   `json:"mode"`
+	Teams []string `json:"teams"`
+	// [Team] -> usernames
+	Members map[string][]string `json:"members"`
+}
+
+func (r LiveRoom) teamState() TeamState {
+	state := TeamState{
+		Mode:    r.TeamMode,
+		Teams:   r.Teams,
+		Members: make(map[string][]string, len(r.Teams)),
+	}
+	for _, team := range r.Teams {
+		state.Members[team] = []string{}
+	}
+	for _, p := range r.Participants {
+		if _, ok := state.Members[p.Team]; ok && !p.IsTeacher {
+			state.Members[p.Team] = append(state.Members[p.Team], p.Username)
+		}
+	}
+	return state
+}
+
+// setTeams turns team mode on with the teams or off when mode is empty. With
+// automatic teams everyone already in the room is put into one, turning teams
+// off takes everyone out of theirs. The participants whose team changed are
+// returned.
+func (r *LiveRoom) setTeams(mode string, teams []string) ([]Participant, error) {
+	if mode == "" {
+		r.TeamMode = ""
+		r.Teams = nil
+		changed := make([]Participant, 0)
+		for i := range r.Participants {
+			p := &r.Participants[i]
+			if p.IsTeacher || p.Team == "" {
+				continue
+			}
+			p.Team = ""
+			changed = append(changed, *p)
+		}
+		return changed, nil
+	}
+	if mode != constants.TeamMode_AUTO && mode != constants.TeamMode_PICK {
+		return nil, fmt.Errorf("unknown team mode: %s", mode)
+	}
+
+	names := make([]string, 0, len(teams))
+	for _, team := range teams {
+		team = strings.TrimSpace(team)
+		if team == "" {
+			return nil, fmt.Errorf("team names cannot be empty")
+		}
+		for _, name := range names {
+			if strings.EqualFold(name, team) {
+				return nil, fmt.Errorf("team %s is listed twice", team)
+			}
+		}
+		names = append(names, team)
+	}
+	if len(names) < minTeams {
+		return nil, fmt.Errorf("team mode needs at least %d teams", minTeams)
+	}
+	r.TeamMode = mode
+	r.Teams = names
+
+	changed := make([]Participant, 0)
+	for i := range r.Participants {
+		p := &r.Participants[i]
+		if p.IsTeacher || r.hasTeam(p.Team) {
+			continue
+		}
+		p.Team = ""
+		if mode == constants.TeamMode_AUTO {
+			p.Team = r.smallestTeam()
+			changed = append(changed, *p)
+		}
+	}
+	return changed, nil
+}
+
+// assignTeam puts a new participant into the smallest team or the one picked.
+// Participants who have not picked yet can pick once they are in the room.
+func (r *LiveRoom) assignTeam(p *Participant, picked string) error {
+	switch r.TeamMode {
+	case constants.TeamMode_AUTO:
+		p.Team = r.smallestTeam()
+	case constants.TeamMode_PICK:
+		if picked != "" && !r.hasTeam(picked) {
+			return fmt.Errorf("please pick one of the teams: %s", strings.Join(r.Teams, ", "))
+		}
+		p.Team = picked
+	default:
+		p.Team = ""
+	}
+	return nil
+}
+
+// pickTeam moves a participant to the team it picked. Teams are scored with
+// all the points of their members, so once the questions have started a
+// participant can only pick a team when it has none yet.
+func (r *LiveRoom) pickTeam(key, team string) (Participant, error) {
+	if r.TeamMode != constants.TeamMode_PICK {
+		return Participant{}, fmt.Errorf("teams are not picked in this room")
+	}
+	p := r.participant(key)
+	if p == nil {
+		return Participant{}, errNotInRoom
+	}
+	if p.Team != "" && r.questionsStarted() {
+		return Participant{}, fmt.Errorf("teams cannot be changed once the questions have started")
+	}
+	if !r.hasTeam(team) {
+		return Participant{}, fmt.Errorf("please pick one of the teams: %s", strings.Join(r.Teams, ", "))
+	}
+	if err := r.assignTeam(p, team); err != nil {
+		return Participant{}, err
+	}
+	return *p, nil
+}
+
+// questionsStarted reports whether a question has been opened in the room.
+func (r *LiveRoom) questionsStarted() bool {
+	return r.Paced || r.Question.QuestionID != "" || len(r.ClosedQuestions) > 0
+}
+
+func (r *LiveRoom) hasTeam(team string) bool {
+	for _, t := range r.Teams {
+		if t == team {
+			return true
+		}
+	}
+	return false
+}
+
+// smallestTeam keeps the teams balanced, ties go to the first listed team.
+func (r *LiveRoom) smallestTeam() string {
+	sizes := make(map[string]int, len(r.Teams))
+	for _, p := range r.Participants {
+		if !p.IsTeacher {
+			sizes[p.Team]++
+		}
+	}
+	smallest := ""
+	for _, team := range r.Teams {
+		if smallest == "" || sizes[team] < sizes[smallest] {
+			smallest = team
+		}
+	}
+	return smallest
+}
+
+// SaveTeamMembers keeps the teams of the participants with the session.
+func (s *PresentationService) SaveTeamMembers(ctx context.Context, sessionID string, participants []Participant) error {
+	for _, p := range participants {
+		if p.Team == "" {
+			continue
+		}
+		err := s.DB.UpsertSessionTeamMember(ctx, repositories.UpsertSessionTeamMemberParams{
+			SessionID:   sessionID,
+			Participant: p.key(),
+			Username:    p.Username,
+			Team:        p.Team,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetTeams keeps the teams of the room with the session, the members of the
+// teams it no longer has are dropped so they stop counting for them.
+func (s *PresentationService) SetTeams(ctx context.Context, sessionID string, teams []string, changed []Participant) error {
+	members := make([]repositories.UpsertSessionTeamMemberParams, 0, len(changed))
+	for _, p := range changed {
+		if p.Team == "" {
+			continue
+		}
+		members = append(members, repositories.UpsertSessionTeamMemberParams{
+			Participant: p.key(),
+			Username:    p.Username,
+			Team:        p.Team,
+		})
+	}
+	return s.DB.SetSessionTeamsTx(ctx, repositories.SetSessionTeamsTxParams{
+		SessionID: sessionID,
+		Teams:     teams,
+		Members:   members,
+	})
+}
+
+// GetTeamLeaderboard adds up the quiz scores of the members of every team.
+func (s *SlideService) GetTeamLeaderboard(sessionID string) ([]TeamLeaderboardEntry, error) {
+	res, err := s.DB.GetTeamLeaderboardBySessionID(context.Background(), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return rankTeamLeaderboard(res), nil
+}
+
+func rankTeamLeaderboard(res []repositories.GetTeamLeaderboardBySessionIDRow) []TeamLeaderboardEntry {
+	leaderboard := make([]TeamLeaderboardEntry, 0, len(res))
+	for i, row := range res {
+		rank := i + 1
+		// teams with the same score share a rank
+		if i > 0 && int(row.Score) == leaderboard[i-1].Score {
+			rank = leaderboard[i-1].Rank
+		}
+		leaderboard = append(leaderboard, TeamLeaderboardEntry{
+			Rank:  rank,
+			Team:  row.Team,
+			Score: int(row.Score),
+		})
+	}
+	return leaderboard
+}
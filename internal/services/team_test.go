@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+func TestSetTeams(t *testing.T) {
+	r := LiveRoom{ID: "room"}
+	_, err := r.setTeams("mixed", []string{"red", "blue"})
+	require.Error(t, err)
+	_, err = r.setTeams(constants.TeamMode_AUTO, []string{"red"})
+	require.Error(t, err)
+	_, err = r.setTeams(constants.TeamMode_AUTO, []string{"red", " "})
+	require.Error(t, err)
+	_, err = r.setTeams(constants.TeamMode_AUTO, []string{"red", "Red"})
+	require.Error(t, err)
+	require.Empty(t, r.TeamMode)
+
+	_, err = r.setTeams(constants.TeamMode_PICK, []string{" red ", "blue"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"red", "blue"}, r.Teams)
+
+	_, err = r.setTeams("", nil)
+	require.NoError(t, err)
+	require.Empty(t, r.TeamMode)
+	require.Empty(t, r.Teams)
+}
+
+func TestAutoTeamsAreBalanced(t *testing.T) {
+	r := LiveRoom{
+		ID: "room",
+		Participants: []Participant{
+			{Username: "host", IsTeacher: true},
+			{Username: "a"},
+			{Username: "b"},
+			{Username: "c"},
+		},
+	}
+	changed, err := r.setTeams(constants.TeamMode_AUTO, []string{"red", "blue"})
+	require.NoError(t, err)
+	require.Len(t, changed, 3)
+
+	p := Participant{Username: "d"}
+	require.NoError(t, r.assignTeam(&p, "red"))
+	require.Equal(t, "blue", p.Team)
+	r.Participants = append(r.Participants, p)
+
+	state := r.teamState()
+	require.Equal(t, []string{"a", "c"}, state.Members["red"])
+	require.Equal(t, []string{"b", "d"}, state.Members["blue"])
+
+	// participants already in a team keep it when the teams change
+	changed, err = r.setTeams(constants.TeamMode_AUTO, []string{"red", "blue", "green"})
+	require.NoError(t, err)
+	require.Empty(t, changed)
+
+	// turning teams off and on again puts everyone back into a team
+	changed, err = r.setTeams("", nil)
+	require.NoError(t, err)
+	require.Len(t, changed, 4)
+	for _, p := range r.Participants {
+		require.Empty(t, p.Team)
+	}
+	changed, err = r.setTeams(constants.TeamMode_AUTO, []string{"red", "blue"})
+	require.NoError(t, err)
+	require.Len(t, changed, 4)
+}
+
+func TestPickTeam(t *testing.T) {
+	r := LiveRoom{ID: "room", Participants: []Participant{{Username: "a"}}}
+	_, err := r.pickTeam("name/a", "red")
+	require.Error(t, err)
+
+	_, err = r.setTeams(constants.TeamMode_PICK, []string{"red", "blue"})
+	require.NoError(t, err)
+	require.Empty(t, r.Participants[0].Team)
+
+	p := Participant{Username: "b"}
+	require.Error(t, r.assignTeam(&p, "green"))
+	require.NoError(t, r.assignTeam(&p, ""))
+	require.Empty(t, p.Team)
+
+	_, err = r.pickTeam("name/a", "green")
+	require.Error(t, err)
+	_, err = r.pickTeam("nobody", "red")
+	require.Error(t, err)
+	picked, err := r.pickTeam("name/a", "blue")
+	require.NoError(t, err)
+	require.Equal(t, "blue", picked.Team)
+	require.Equal(t, "blue", r.Participants[0].Team)
+
+	// teams are locked once a question opens, but not for those without one
+	r.Participants = append(r.Participants, Participant{Username: "c"})
+	r.Question = QuestionWindow{QuestionID: "q1"}
+	_, err = r.pickTeam("name/a", "red")
+	require.Error(t, err)
+	require.Equal(t, "blue", r.Participants[0].Team)
+	picked, err = r.pickTeam("name/c", "red")
+	require.NoError(t, err)
+	require.Equal(t, "red", picked.Team)
+}
+
+func TestRankTeamLeaderboard(t *testing.T) {
+	leaderboard := rankTeamLeaderboard([]repositories.GetTeamLeaderboardBySessionIDRow{
+		{Team: "red", Score: 30},
+		{Team: "blue", Score: 30},
+		{Team: "green", Score: 10},
+	})
+	require.Equal(t, []TeamLeaderboardEntry{
+		{Rank: 1, Team: "red", Score: 30},
+		{Rank: 1, Team: "blue", Score: 30},
+		{Rank: 3, Team: "green", Score: 10},
+	}, leaderboard)
+}
+
+func TestSetTeamsDropsOldMembers(t *testing.T) {
+	db, config := newTestStore(t)
+	ctx := context.Background()
+	svc := NewPresentationService(db, NewRoomManager(), NewSlideService(db, &config), &config)
+	sessionID := utils.RandomString(12)
+
+	err := svc.SetTeams(ctx, sessionID, []string{"red", "blue"}, []Participant{
+		{Username: "a", Team: "red"},
+		{Username: "b", Team: "blue"},
+	})
+	require.NoError(t, err)
+
+	// blue is renamed, its members are moved or dropped
+	err = svc.SetTeams(ctx, sessionID, []string{"red", "green"}, []Participant{{Username: "c", Team: "green"}})
+	require.NoError(t, err)
+	members, err := db.ListSessionTeamMembers(ctx, sessionID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.Equal(t, "c", members[0].Username)
+	require.Equal(t, "a", members[1].Username)
+
+	// turning teams off drops everyone
+	require.NoError(t, svc.SetTeams(ctx, sessionID, nil, nil))
+	members, err = db.ListSessionTeamMembers(ctx, sessionID)
+	require.NoError(t, err)
+	require.Empty(t, members)
+}
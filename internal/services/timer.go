@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+const questionTickInterval = time.Second
+
+var errQuestionClosed = errors.New("time is up for this question")
+
+type QuestionTick struct {
+	QuestionID string `json:"question_id"`
+	Remaining  int    `json:"remaining"`
+}
+
+type QuestionClosed struct {
+	QuestionID string `json:"question_id"`
+}
+
+// questionTimer counts down the current question of a room on this instance,
+// the answering window itself lives in the room state.
+type questionTimer struct {
+	window QuestionWindow
+	stop   chan struct{}
+}
+
+// questionTimers are the countdowns of the rooms hosted on this instance.
+type questionTimers struct {
+	server      *Server
+	broadcaster Broadcaster
+	// onClose is called once the time of a question is up
+	onClose func(roomID, questionID string)
+
+	mu sync.Mutex
+	// [Room ID] -> timer of the current question
+	timers map[string]*questionTimer
+}
+
+func newQuestionTimers(server *Server, broadcaster Broadcaster, onClose func(roomID, questionID string)) *questionTimers {
+	return &questionTimers{
+		server:      server,
+		broadcaster: broadcaster,
+		onClose:     onClose,
+		timers:      make(map[string]*questionTimer),
+	}
+}
+
+// start opens the question at index for answering and, when it has a time
+// limit, counts it down and closes it on the server. When the question cannot be
+// opened the room is left without an open question, so answers do not go to the
+// one it moved from.
+func (t *questionTimers) start(roomID string, index int) {
+	t.stop(roomID)
+
+	cctx := context.Background()
+	rooms := t.server.PresentationService.Rooms
+	question, err := t.server.QuestionService.DB.GetQuestionBySlideAndIndex(cctx, repositories.GetQuestionBySlideAndIndexParams{
+		SlideID: roomID,
+		Index:   int16(index),
+	})
+	if err != nil {
+		fmt.Println("start question timer:", err)
+		_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+			r.leaveQuestion(index)
+			return nil
+		})
+		if err != nil {
+			fmt.Println("start question timer:", err)
+		}
+		return
+	}
+
+	window := QuestionWindow{
+		QuestionID: question.ID,
+		OpenedAt:   time.Now(),
+	}
+	if question.TimeLimit > 0 {
+		window.Deadline = window.OpenedAt.Add(time.Duration(question.TimeLimit) * time.Second)
+	}
+
+	opened := false
+	_, err = rooms.Update(cctx, roomID, func(r *LiveRoom) error {
+		if err := r.openQuestion(window); err != nil {
+			r.leaveQuestion(index)
+			return nil
+		}
+		opened = true
+		return nil
+	})
+	if err != nil {
+		fmt.Println("start question timer:", err)
+		return
+	}
+	if !opened || window.Deadline.IsZero() {
+		return
+	}
+
+	timer := &questionTimer{
+		window: window,
+		stop:   make(chan struct{}),
+	}
+	t.mu.Lock()
+	t.timers[roomID] = timer
+	t.mu.Unlock()
+	go t.run(roomID, timer)
+}
+
+func (t *questionTimers) run(roomID string, timer *questionTimer) {
+	rooms := t.server.PresentationService.Rooms
+	broadcaster := t.broadcaster
+	ticker := time.NewTicker(questionTickInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(time.Until(timer.window.Deadline))
+	defer deadline.Stop()
+
+	questionID := timer.window.QuestionID
+	broadcaster.BroadcastToRoom("/", roomID, "questionTick", timer.tick())
+	for {
+		select {
+		case <-timer.stop:
+			return
+		case <-ticker.C:
+			// the question may have been moved on or closed from another instance
+			r, ok, err := rooms.Get(context.Background(), roomID)
+			if err != nil || !ok || r.Question.QuestionID != questionID || r.ClosedQuestions[questionID] {
+				t.remove(roomID, timer)
+				return
+			}
+			broadcaster.BroadcastToRoom("/", roomID, "questionTick", timer.tick())
+		case <-deadline.C:
+			t.remove(roomID, timer)
+
+			closed := false
+			_, err := rooms.Update(context.Background(), roomID, func(r *LiveRoom) error {
+				closed = r.closeQuestion(questionID)
+				return nil
+			})
+			if err != nil || !closed {
+				return
+			}
+
+			broadcaster.BroadcastToRoom("/", roomID, "questionClosed", QuestionClosed{
+				QuestionID: questionID,
+			})
+			if t.onClose != nil {
+				t.onClose(roomID, questionID)
+			}
+			return
+		}
+	}
+}
+
+func (t *questionTimer) tick() QuestionTick {
+	remaining := time.Until(t.window.Deadline).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return QuestionTick{
+		QuestionID: t.window.QuestionID,
+		Remaining:  int(remaining / time.Second),
+	}
+}
+
+// openQuestion starts the answering window of a question that has not been
+// closed yet. A timed question keeps its deadline, it is not opened again once
+// its time is up.
+func (r *LiveRoom) openQuestion(window QuestionWindow) error {
+	if r.ClosedQuestions[window.QuestionID] || r.expired(window.QuestionID, time.Now()) {
+		return errQuestionClosed
+	}
+	r.Question = window
+	if !window.Deadline.IsZero() {
+		if r.Deadlines == nil {
+			r.Deadlines = make(map[string]time.Time)
+		}
+		r.Deadlines[window.QuestionID] = window.Deadline
+	}
+	return nil
+}
+
+// leaveQuestion takes a room that moved to index off its previous question when
+// the question there cannot be opened. A room that has moved on again since is
+// left alone.
+func (r *LiveRoom) leaveQuestion(index int) {
+	if r.State == index {
+		r.Question = QuestionWindow{}
+	}
+}
+
+// expired reports whether the time of a timed question is up, whether or not
+// the room is still on it.
+func (r *LiveRoom) expired(questionID string, now time.Time) bool {
+	deadline, ok := r.Deadlines[questionID]
+	if r.Question.QuestionID == questionID {
+		deadline, ok = r.Question.Deadline, !r.Question.Deadline.IsZero()
+	}
+	return ok && now.After(deadline)
+}
+
+// closeQuestion closes the question if it is the current one. It reports false
+// when the question was already closed or has been moved on from.
+func (r *LiveRoom) closeQuestion(questionID string) bool {
+	if questionID == "" || r.Question.QuestionID != questionID || r.ClosedQuestions[questionID] {
+		return false
+	}
+	if r.ClosedQuestions == nil {
+		r.ClosedQuestions = make(map[string]bool)
+	}
+	r.ClosedQuestions[questionID] = true
+	// quiz rooms show the results with the leaderboard
+	if r.IsQuiz {
+		r.Question.Revealed = true
+	}
+	return true
+}
+
+// closeCurrentQuestion ends the answering window of the current question before
+// its time is up, the caller stops its countdown. It reports false when the
+// question was already closed.
+func closeCurrentQuestion(ctx context.Context, rooms SessionStore, roomID string) (string, bool, error) {
+	questionID, closed := "", false
+	_, err := rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		questionID = r.Question.QuestionID
+		closed = r.closeQuestion(questionID)
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return questionID, closed, nil
+}
+
+// answerWindow returns when the question was opened and its deadline, zero if untimed.
+func answerWindow(r LiveRoom, questionID string) (time.Time, time.Time, bool) {
+	if r.Question.QuestionID != questionID {
+		return time.Time{}, time.Time{}, false
+	}
+	return r.Question.OpenedAt, r.Question.Deadline, true
+}
+
+// remove forgets timer if it is still the one running for the room.
+func (t *questionTimers) remove(roomID string, timer *questionTimer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.timers[roomID] == timer {
+		delete(t.timers, roomID)
+	}
+}
+
+// stop ends the countdown of the room, if it has one.
+func (t *questionTimers) stop(roomID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timer, ok := t.timers[roomID]
+	if !ok {
+		return
+	}
+	close(timer.stop)
+	delete(t.timers, roomID)
+}
+
+// checkQuestionOpen rejects answers to a question whose time is up or that the
+// host has locked.
+func checkQuestionOpen(r LiveRoom, questionID string) error {
+	if r.ClosedQuestions[questionID] {
+		return errQuestionClosed
+	}
+	if r.Question.QuestionID == questionID && r.Question.Locked {
+		return errAnswersLocked
+	}
+	if r.expired(questionID, time.Now()) {
+		return errQuestionClosed
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimedQuestionExpiresAfterMove(t *testing.T) {
+	r := LiveRoom{ID: "room", State: 1}
+	require.NoError(t, r.openQuestion(QuestionWindow{QuestionID: "timed", OpenedAt: time.Now(), Deadline: time.Now().Add(time.Hour)}))
+	require.NoError(t, r.openQuestion(QuestionWindow{QuestionID: "untimed", OpenedAt: time.Now()}))
+	require.NoError(t, checkQuestionOpen(r, "timed"))
+
+	// the room has moved on when the time of the first question is up
+	r.Deadlines["timed"] = time.Now().Add(-time.Second)
+	require.ErrorIs(t, checkQuestionOpen(r, "timed"), errQuestionClosed)
+	require.NoError(t, checkQuestionOpen(r, "untimed"))
+
+	// going back does not give it a new timer
+	require.ErrorIs(t, r.openQuestion(QuestionWindow{QuestionID: "timed", OpenedAt: time.Now(), Deadline: time.Now().Add(time.Hour)}), errQuestionClosed)
+	require.Equal(t, "untimed", r.Question.QuestionID)
+
+	// the room takes no answers for the question it moved from, unless it has
+	// moved on again
+	r.State = 3
+	r.leaveQuestion(2)
+	require.Equal(t, "untimed", r.Question.QuestionID)
+	r.leaveQuestion(3)
+	require.Empty(t, r.Question.QuestionID)
+}
@@ -2,9 +2,14 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
 	"github.com/vtv-us/kahoot-backend/internal/entities"
 	"github.com/vtv-us/kahoot-backend/internal/repositories"
 	"github.com/vtv-us/kahoot-backend/internal/utils"
@@ -22,18 +27,60 @@ func NewUserQuestionService(db repositories.Store, c *utils.Config) *UserQuestio
 	}
 }
 
+var errUserQuestionNotFound = errors.New("question does not exist")
+
+// UserQuestionItem is a question of the audience as a participant sees it,
+// Voted tells whether the participant voted for it and Pinned whether the
+// host put it on the presenter screen.
+type UserQuestionItem struct {
+	entities.UserQuestion
+	Voted  bool `json:"voted"`
+	Pinned bool `json:"pinned"`
+}
+
+type UserQuestionList struct {
+	Questions []UserQuestionItem `json:"questions"`
+}
+
+// PendingQuestions are the questions waiting for a host to approve them.
+type PendingQuestions struct {
+	Questions []entities.UserQuestion `json:"questions"`
+}
+
+// PinnedQuestion is the question on the presenter screen, Question is null
+// when there is none.
+type PinnedQuestion struct {
+	Question *entities.UserQuestion `json:"question"`
+}
+
+// PostQuestionRequest is a question of the audience. Moderated questions wait
+// for a host to approve them.
 type PostQuestionRequest struct {
-	SlideID  string
-	Username string
-	Content  string
+	SessionID string
+	SlideID   string
+	Username  string
+	Content   string
+	Anonymous bool
+	Moderated bool
 }
 
 func (s *UserQuestionService) PostQuestion(ctx context.Context, req PostQuestionRequest) (entities.UserQuestion, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return entities.UserQuestion{}, fmt.Errorf("please enter your question")
+	}
+	status := constants.UserQuestionStatus_APPROVED
+	if req.Moderated {
+		status = constants.UserQuestionStatus_PENDING
+	}
 	question, err := s.DB.UpsertUserQuestion(ctx, repositories.UpsertUserQuestionParams{
 		QuestionID: uuid.NewString(),
 		SlideID:    req.SlideID,
 		Username:   req.Username,
-		Content:    req.Content,
+		Content:    content,
+		SessionID:  req.SessionID,
+		Status:     status,
+		Anonymous:  req.Anonymous,
 	})
 	if err != nil {
 		return entities.UserQuestion{}, err
@@ -42,24 +89,159 @@ func (s *UserQuestionService) PostQuestion(ctx context.Context, req PostQuestion
 	return question.UserQuestion, nil
 }
 
-func (s *UserQuestionService) ListQuestionBySlideID(ctx context.Context, slideID string) ([]entities.UserQuestion, error) {
-	questions, err := s.DB.ListUserQuestion(ctx, slideID)
+func (s *UserQuestionService) ListQuestionBySessionID(ctx context.Context, sessionID string) ([]entities.UserQuestion, error) {
+	questions, err := s.DB.ListUserQuestionBySession(ctx, sessionID)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(questions)
 
 	entQuestions := make([]entities.UserQuestion, len(questions))
 	for i, q := range questions {
 		entQuestions[i] = q.UserQuestion
 	}
-	fmt.Println(entQuestions)
 
 	return entQuestions, nil
 }
 
-func (s *UserQuestionService) UpvoteQuestion(ctx context.Context, questionID string) (entities.UserQuestion, error) {
-	question, err := s.DB.UpvoteUserQuestion(ctx, questionID)
+// ListQuestionForVoter lists the approved questions of the session in the
+// sort order with the ones the voter voted for.
+func (s *UserQuestionService) ListQuestionForVoter(ctx context.Context, sessionID, voter, sort string) ([]UserQuestionItem, error) {
+	questions, err := s.ListQuestionBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	voted, err := s.DB.ListVotedUserQuestionIDs(ctx, repositories.ListVotedUserQuestionIDsParams{
+		SessionID: sessionID,
+		Voter:     voter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sortUserQuestions(userQuestionItems(questions, voted), sort)
+}
+
+// userQuestionItems keeps the approved questions as the audience sees them.
+func userQuestionItems(questions []entities.UserQuestion, voted []string) []UserQuestionItem {
+	votedSet := make(map[string]bool, len(voted))
+	for _, id := range voted {
+		votedSet[id] = true
+	}
+	items := make([]UserQuestionItem, 0, len(questions))
+	for _, q := range questions {
+		if q.Status != constants.UserQuestionStatus_APPROVED {
+			continue
+		}
+		items = append(items, UserQuestionItem{
+			UserQuestion: publicUserQuestion(q),
+			Voted:        votedSet[q.QuestionID],
+		})
+	}
+	return items
+}
+
+// sortUserQuestions orders the questions by votes with top, newest first with
+// newest, the default, and keeps only the answered ones with answered.
+func sortUserQuestions(items []UserQuestionItem, order string) ([]UserQuestionItem, error) {
+	newest := func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	}
+	switch order {
+	case "", constants.UserQuestionSort_NEWEST:
+		sort.SliceStable(items, newest)
+	case constants.UserQuestionSort_TOP:
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Votes != items[j].Votes {
+				return items[i].Votes > items[j].Votes
+			}
+			return newest(i, j)
+		})
+	case constants.UserQuestionSort_ANSWERED:
+		answered := items[:0]
+		for _, item := range items {
+			if item.Answered {
+				answered = append(answered, item)
+			}
+		}
+		items = answered
+		sort.SliceStable(items, newest)
+	default:
+		return nil, fmt.Errorf("unknown sort order: %s", order)
+	}
+	return items, nil
+}
+
+// publicUserQuestion hides who asked an anonymous question.
+func publicUserQuestion(q entities.UserQuestion) entities.UserQuestion {
+	if q.Anonymous {
+		q.Username = ""
+	}
+	return q
+}
+
+// ListPendingQuestions is the moderation queue of the session, oldest first.
+func (s *UserQuestionService) ListPendingQuestions(ctx context.Context, sessionID string) ([]entities.UserQuestion, error) {
+	questions, err := s.ListQuestionBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]entities.UserQuestion, 0)
+	for i := len(questions) - 1; i >= 0; i-- {
+		if questions[i].Status == constants.UserQuestionStatus_PENDING {
+			pending = append(pending, questions[i])
+		}
+	}
+	return pending, nil
+}
+
+// GetSessionQuestion returns a question of the audience of the session.
+func (s *UserQuestionService) GetSessionQuestion(ctx context.Context, sessionID, questionID string) (entities.UserQuestion, error) {
+	question, err := s.DB.GetUserQuestion(ctx, questionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entities.UserQuestion{}, errUserQuestionNotFound
+		}
+		return entities.UserQuestion{}, err
+	}
+	if question.SessionID != sessionID {
+		return entities.UserQuestion{}, errUserQuestionNotFound
+	}
+
+	return question.UserQuestion, nil
+}
+
+// ModerateQuestion approves or rejects a question of the session.
+func (s *UserQuestionService) ModerateQuestion(ctx context.Context, sessionID, questionID, status string) (entities.UserQuestion, error) {
+	if _, err := s.GetSessionQuestion(ctx, sessionID, questionID); err != nil {
+		return entities.UserQuestion{}, err
+	}
+
+	question, err := s.DB.UpdateUserQuestionStatus(ctx, repositories.UpdateUserQuestionStatusParams{
+		QuestionID: questionID,
+		Status:     status,
+	})
+	if err != nil {
+		return entities.UserQuestion{}, err
+	}
+
+	return question.UserQuestion, nil
+}
+
+// EditQuestion lets a host fix the wording of a question of the session.
+func (s *UserQuestionService) EditQuestion(ctx context.Context, sessionID, questionID, content string) (entities.UserQuestion, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return entities.UserQuestion{}, fmt.Errorf("please enter the question")
+	}
+	if _, err := s.GetSessionQuestion(ctx, sessionID, questionID); err != nil {
+		return entities.UserQuestion{}, err
+	}
+
+	question, err := s.DB.UpdateUserQuestionContent(ctx, repositories.UpdateUserQuestionContentParams{
+		QuestionID: questionID,
+		Content:    content,
+	})
 	if err != nil {
 		return entities.UserQuestion{}, err
 	}
@@ -67,7 +249,36 @@ func (s *UserQuestionService) UpvoteQuestion(ctx context.Context, questionID str
 	return question.UserQuestion, nil
 }
 
-func (s *UserQuestionService) ToggleUserQuestionAnswered(ctx context.Context, questionID string) (entities.UserQuestion, error) {
+// ToggleVote upvotes a question of the session or takes the vote back, every
+// voter has one vote per question.
+func (s *UserQuestionService) ToggleVote(ctx context.Context, sessionID, questionID, voter string) (entities.UserQuestion, bool, error) {
+	question, err := s.GetSessionQuestion(ctx, sessionID, questionID)
+	if err != nil {
+		return entities.UserQuestion{}, false, err
+	}
+	if question.Status != constants.UserQuestionStatus_APPROVED {
+		return entities.UserQuestion{}, false, errUserQuestionNotFound
+	}
+
+	updated, voted, err := s.DB.ToggleUserQuestionVoteTx(ctx, questionID, voter)
+	if err != nil {
+		return entities.UserQuestion{}, false, err
+	}
+
+	return updated.UserQuestion, voted, nil
+}
+
+// ToggleUserQuestionAnswered marks an approved question of the session as
+// answered or not, the others are not shown to the audience.
+func (s *UserQuestionService) ToggleUserQuestionAnswered(ctx context.Context, sessionID, questionID string) (entities.UserQuestion, error) {
+	current, err := s.GetSessionQuestion(ctx, sessionID, questionID)
+	if err != nil {
+		return entities.UserQuestion{}, err
+	}
+	if current.Status != constants.UserQuestionStatus_APPROVED {
+		return entities.UserQuestion{}, errors.New("only approved questions can be marked answered")
+	}
+
 	question, err := s.DB.ToggleUserQuestionAnswered(ctx, questionID)
 	if err != nil {
 		return entities.UserQuestion{}, err
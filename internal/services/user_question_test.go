@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/constants"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+	"github.com/vtv-us/kahoot-backend/internal/utils"
+)
+
+func TestUserQuestionItems(t *testing.T) {
+	items := userQuestionItems([]entities.UserQuestion{
+		{QuestionID: "first", Status: constants.UserQuestionStatus_APPROVED, Username: "a"},
+		{QuestionID: "second", Status: constants.UserQuestionStatus_APPROVED, Username: "b", Anonymous: true},
+		{QuestionID: "pending", Status: constants.UserQuestionStatus_PENDING},
+		{QuestionID: "rejected", Status: constants.UserQuestionStatus_REJECTED},
+	}, []string{"second"})
+	require.Len(t, items, 2)
+	require.False(t, items[0].Voted)
+	require.Equal(t, "a", items[0].Username)
+	require.True(t, items[1].Voted)
+	require.Empty(t, items[1].Username)
+}
+
+func TestSortUserQuestions(t *testing.T) {
+	now := time.Now()
+	items := func() []UserQuestionItem {
+		return []UserQuestionItem{
+			{UserQuestion: entities.UserQuestion{QuestionID: "old", Votes: 3, CreatedAt: now.Add(-time.Hour)}},
+			{UserQuestion: entities.UserQuestion{QuestionID: "new", Votes: 1, CreatedAt: now, Answered: true}},
+			{UserQuestion: entities.UserQuestion{QuestionID: "mid", Votes: 3, CreatedAt: now.Add(-time.Minute)}},
+		}
+	}
+	ids := func(items []UserQuestionItem) []string {
+		res := make([]string, 0, len(items))
+		for _, item := range items {
+			res = append(res, item.QuestionID)
+		}
+		return res
+	}
+
+	sorted, err := sortUserQuestions(items(), "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"new", "mid", "old"}, ids(sorted))
+	sorted, err = sortUserQuestions(items(), constants.UserQuestionSort_TOP)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mid", "old", "new"}, ids(sorted))
+	sorted, err = sortUserQuestions(items(), constants.UserQuestionSort_ANSWERED)
+	require.NoError(t, err)
+	require.Equal(t, []string{"new"}, ids(sorted))
+	_, err = sortUserQuestions(items(), "random")
+	require.Error(t, err)
+}
+
+func TestQuestionVoter(t *testing.T) {
+	ctx := context.Background()
+	rooms := NewRoomManager()
+	roomID := utils.RandomString(12)
+	_, err := rooms.Host(ctx, roomID, Participant{Username: "teacher", UserID: "teacher", SID: "teacher"}, false, "")
+	require.NoError(t, err)
+	require.NoError(t, rooms.Join(ctx, roomID, Participant{Username: "Guest", ID: "guest", SID: "guest"}))
+
+	_, _, err = questionVoter(ctx, rooms, roomID, "guest/guest")
+	require.Error(t, err)
+	_, err = rooms.Update(ctx, roomID, func(r *LiveRoom) error {
+		r.SessionID = "session"
+		return nil
+	})
+	require.NoError(t, err)
+
+	r, voter, err := questionVoter(ctx, rooms, roomID, "guest/guest")
+	require.NoError(t, err)
+	require.Equal(t, "session", r.SessionID)
+	require.Equal(t, "guest/guest", voter)
+	_, voter, err = questionVoter(ctx, rooms, roomID, "user/teacher")
+	require.NoError(t, err)
+	require.Equal(t, "user/teacher", voter)
+	_, _, err = questionVoter(ctx, rooms, roomID, "nobody")
+	require.Error(t, err)
+}
+
+func TestToggleUserQuestionVote(t *testing.T) {
+	db, config := newTestStore(t)
+	ctx := context.Background()
+	service := NewUserQuestionService(db, &config)
+
+	sessionID := utils.RandomString(12)
+	question, err := service.PostQuestion(ctx, PostQuestionRequest{
+		SessionID: sessionID,
+		SlideID:   utils.RandomString(12),
+		Username:  "Guest",
+		Content:   "why?",
+	})
+	require.NoError(t, err)
+
+	_, _, err = service.ToggleVote(ctx, utils.RandomString(12), question.QuestionID, "user/a")
+	require.Error(t, err)
+
+	// the same voter voting again takes the vote back
+	for i := 0; i < 3; i++ {
+		_, _, err = service.ToggleVote(ctx, sessionID, question.QuestionID, "user/a")
+		require.NoError(t, err)
+	}
+	stored, err := db.GetUserQuestion(ctx, question.QuestionID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), stored.Votes)
+
+	voted, err := service.ListQuestionForVoter(ctx, sessionID, "user/b", "")
+	require.NoError(t, err)
+	require.False(t, voted[0].Voted)
+	updated, ok, err := service.ToggleVote(ctx, sessionID, question.QuestionID, "user/b")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int32(2), updated.Votes)
+	voted, err = service.ListQuestionForVoter(ctx, sessionID, "user/b", "")
+	require.NoError(t, err)
+	require.True(t, voted[0].Voted)
+
+	updated, ok, err = service.ToggleVote(ctx, sessionID, question.QuestionID, "user/b")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, int32(1), updated.Votes)
+
+	// moderated questions wait for a host and cannot be voted for
+	pending, err := service.PostQuestion(ctx, PostQuestionRequest{
+		SessionID: sessionID,
+		SlideID:   question.SlideID,
+		Username:  "Guest",
+		Content:   "  secret?  ",
+		Anonymous: true,
+		Moderated: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, constants.UserQuestionStatus_PENDING, pending.Status)
+	require.Equal(t, "secret?", pending.Content)
+	_, _, err = service.ToggleVote(ctx, sessionID, pending.QuestionID, "user/b")
+	require.Error(t, err)
+	queue, err := service.ListPendingQuestions(ctx, sessionID)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	voted, err = service.ListQuestionForVoter(ctx, sessionID, "user/b", "")
+	require.NoError(t, err)
+	require.Len(t, voted, 1)
+
+	edited, err := service.EditQuestion(ctx, sessionID, pending.QuestionID, "what is secret?")
+	require.NoError(t, err)
+	require.Equal(t, "what is secret?", edited.Content)
+	approved, err := service.ModerateQuestion(ctx, sessionID, pending.QuestionID, constants.UserQuestionStatus_APPROVED)
+	require.NoError(t, err)
+	require.Equal(t, constants.UserQuestionStatus_APPROVED, approved.Status)
+	voted, err = service.ListQuestionForVoter(ctx, sessionID, "user/b", "")
+	require.NoError(t, err)
+	require.Len(t, voted, 2)
+	require.Empty(t, voted[0].Username)
+	_, err = service.ModerateQuestion(ctx, utils.RandomString(12), pending.QuestionID, constants.UserQuestionStatus_REJECTED)
+	require.Error(t, err)
+}
+
+func TestToggleUserQuestionAnswered(t *testing.T) {
+	db, config := newTestStore(t)
+	ctx := context.Background()
+	service := NewUserQuestionService(db, &config)
+
+	sessionID := utils.RandomString(12)
+	question, err := service.PostQuestion(ctx, PostQuestionRequest{
+		SessionID: sessionID,
+		SlideID:   utils.RandomString(12),
+		Username:  "Guest",
+		Content:   "why?",
+	})
+	require.NoError(t, err)
+
+	// questions of other sessions cannot be changed
+	_, err = service.ToggleUserQuestionAnswered(ctx, utils.RandomString(12), question.QuestionID)
+	require.Error(t, err)
+	answered, err := service.ToggleUserQuestionAnswered(ctx, sessionID, question.QuestionID)
+	require.NoError(t, err)
+	require.True(t, answered.Answered)
+
+	// nor can questions waiting for approval
+	pending, err := service.PostQuestion(ctx, PostQuestionRequest{
+		SessionID: sessionID,
+		SlideID:   question.SlideID,
+		Username:  "Guest",
+		Content:   "secret?",
+		Moderated: true,
+	})
+	require.NoError(t, err)
+	_, err = service.ToggleUserQuestionAnswered(ctx, sessionID, pending.QuestionID)
+	require.Error(t, err)
+}
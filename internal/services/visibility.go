@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+)
+
+var (
+	errAnswersLocked = errors.New("answering is locked for this question")
+	errNoQuestion    = errors.New("there is no question open in the room")
+)
+
+// RoomState is the question a room is on and what the audience can do with it.
+type RoomState struct {
+	State      int    `json:"state"`
+	QuestionID string `json:"question_id"`
+	Locked     bool   `json:"locked"`
+	Revealed   bool   `json:"revealed"`
+}
+
+func (r LiveRoom) roomState() RoomState {
+	return RoomState{
+		State:      r.State,
+		QuestionID: r.Question.QuestionID,
+		Locked:     r.Question.Locked,
+		Revealed:   r.Question.Revealed,
+	}
+}
+
+// hostRoom is the socket room of the hosts of a room, they get the results of
+// a question before they are revealed.
+func hostRoom(roomID string) string {
+	return roomID + "/host"
+}
+
+// resultsRevealed reports whether the audience can see the results of the
+// question, only those of the current question can be revealed.
+func resultsRevealed(r LiveRoom, questionID string) bool {
+	return questionID != "" && r.Question.QuestionID == questionID && r.Question.Revealed
+}
+
+// setAnswersLocked stops or resumes answering the current question.
+func (r *LiveRoom) setAnswersLocked(locked bool) error {
+	if r.Question.QuestionID == "" {
+		return errNoQuestion
+	}
+	r.Question.Locked = locked
+	return nil
+}
+
+// setResultsRevealed shows or hides the results of the current question.
+func (r *LiveRoom) setResultsRevealed(revealed bool) error {
+	if r.Question.QuestionID == "" {
+		return errNoQuestion
+	}
+	r.Question.Revealed = revealed
+	return nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionLockAndReveal(t *testing.T) {
+	r := LiveRoom{ID: "room", State: 1}
+	require.ErrorIs(t, r.setAnswersLocked(true), errNoQuestion)
+	require.ErrorIs(t, r.setResultsRevealed(true), errNoQuestion)
+
+	require.NoError(t, r.openQuestion(QuestionWindow{QuestionID: "first"}))
+	require.NoError(t, checkQuestionOpen(r, "first"))
+	require.False(t, resultsRevealed(r, "first"))
+
+	require.NoError(t, r.setAnswersLocked(true))
+	require.ErrorIs(t, checkQuestionOpen(r, "first"), errAnswersLocked)
+	require.Equal(t, RoomState{State: 1, QuestionID: "first", Locked: true}, r.roomState())
+	require.NoError(t, r.setAnswersLocked(false))
+	require.NoError(t, checkQuestionOpen(r, "first"))
+
+	require.NoError(t, r.setResultsRevealed(true))
+	require.True(t, resultsRevealed(r, "first"))
+	require.False(t, resultsRevealed(r, "second"))
+	require.NoError(t, r.setResultsRevealed(false))
+	require.False(t, resultsRevealed(r, "first"))
+
+	// a new question starts unlocked and hidden
+	require.NoError(t, r.setAnswersLocked(true))
+	require.NoError(t, r.setResultsRevealed(true))
+	require.NoError(t, r.openQuestion(QuestionWindow{QuestionID: "second"}))
+	require.NoError(t, checkQuestionOpen(r, "second"))
+	require.False(t, resultsRevealed(r, "second"))
+
+	// closing a quiz question reveals its results
+	r.IsQuiz = true
+	require.True(t, r.closeQuestion("second"))
+	require.True(t, resultsRevealed(r, "second"))
+	require.ErrorIs(t, checkQuestionOpen(r, "second"), errQuestionClosed)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vtv-us/kahoot-backend/internal/repositories"
+)
+
+const (
+	maxTextAnswerLength = 280
+	// maxWordCloudWords keeps the word cloud readable on the presentation screen
+	maxWordCloudWords = 100
+)
+
+// stopWords are left out of the word cloud, they say nothing about the answers.
+var stopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "am": true,
+	"an": true, "and": true, "any": true, "are": true, "as": true, "at": true,
+	"be": true, "because": true, "been": true, "but": true, "by": true, "can": true,
+	"could": true, "did": true, "do": true, "does": true, "for": true, "from": true,
+	"had": true, "has": true, "have": true, "he": true, "her": true, "him": true,
+	"his": true, "how": true, "i": true, "if": true, "in": true, "into": true,
+	"is": true, "it": true, "its": true, "just": true, "me": true, "more": true,
+	"my": true, "no": true, "not": true, "of": true, "on": true, "or": true,
+	"our": true, "out": true, "she": true, "so": true, "some": true, "than": true,
+	"that": true, "the": true, "their": true, "them": true, "then": true, "there": true,
+	"these": true, "they": true, "this": true, "to": true, "too": true, "up": true,
+	"us": true, "very": true, "was": true, "we": true, "were": true, "what": true,
+	"when": true, "which": true, "who": true, "why": true, "will": true, "with": true,
+	"would": true, "you": true, "your": true,
+}
+
+// stemSuffixes are cut from the end of a word when stemming, longest first.
+var stemSuffixes = []string{"ingly", "edly", "ing", "ies", "ied", "ed", "es", "ly", "s"}
+
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// WordCloud is how often every word was used in the answers to a paragraph question.
+type WordCloud struct {
+	QuestionID string      `json:"question_id"`
+	Responses  int         `json:"responses"`
+	Words      []WordCount `json:"words"`
+}
+
+// validateTextAnswer checks a free text answer and returns it with its spaces
+// cleaned up.
+func validateTextAnswer(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("please enter your answer")
+	}
+	if utf8.RuneCountInString(text) > maxTextAnswerLength {
+		return "", fmt.Errorf("answer must be at most %d characters", maxTextAnswerLength)
+	}
+	return text, nil
+}
+
+// stemWord reduces a word to a rough stem so "running" and "runs" count as one.
+// Short words are kept as they are.
+func stemWord(word string) string {
+	for _, suffix := range stemSuffixes {
+		stem := strings.TrimSuffix(word, suffix)
+		if stem == word || utf8.RuneCountInString(stem) < 3 {
+			continue
+		}
+		// "class" and "bus" are not plurals
+		if suffix == "s" && (strings.HasSuffix(stem, "s") || strings.HasSuffix(stem, "u")) {
+			return word
+		}
+		if suffix == "ies" || suffix == "ied" {
+			return stem + "y"
+		}
+		// "stopped" -> "stop"
+		if n := len(stem); n > 1 && stem[n-1] == stem[n-2] && !strings.HasSuffix(stem, "ss") && !strings.HasSuffix(stem, "ll") {
+			stem = stem[:n-1]
+		}
+		return stem
+	}
+	return word
+}
+
+// countWords lowercases the answers, splits them into words and counts the
+// words that are not stop words, most used first. Every answer counts a word
+// once.
+func countWords(texts []string, stem bool) []WordCount {
+	counts := make(map[string]int)
+	for _, text := range texts {
+		seen := make(map[string]bool)
+		words := strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+			return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '\''
+		})
+		for _, word := range words {
+			word = strings.TrimSuffix(strings.Trim(word, "'"), "'s")
+			if word == "" || stopWords[word] {
+				continue
+			}
+			if stem {
+				word = stemWord(word)
+			}
+			if !seen[word] {
+				seen[word] = true
+				counts[word]++
+			}
+		}
+	}
+
+	res := make([]WordCount, 0, len(counts))
+	for word, count := range counts {
+		res = append(res, WordCount{Word: word, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Word < res[j].Word
+	})
+	if len(res) > maxWordCloudWords {
+		res = res[:maxWordCloudWords]
+	}
+	return res
+}
+
+// SaveTextAnswer keeps the text answer of the participant with the key.
+func (s *SlideService) SaveTextAnswer(sessionID, participant, username, slideID, questionID, content string) error {
+	_, err := s.DB.UpsertTextAnswer(context.Background(), repositories.UpsertTextAnswerParams{
+		SessionID:   sessionID,
+		QuestionID:  questionID,
+		Participant: participant,
+		Username:    username,
+		SlideID:     slideID,
+		Content:     content,
+	})
+	return err
+}
+
+// GetWordCloud aggregates the free text answers to a question of the session.
+func (s *SlideService) GetWordCloud(sessionID, questionID string, stem bool) (WordCloud, error) {
+	res, err := s.DB.ListTextAnswersByQuestion(context.Background(), repositories.ListTextAnswersByQuestionParams{
+		SessionID:  sessionID,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		return WordCloud{}, err
+	}
+
+	texts := make([]string, 0, len(res))
+	for _, answer := range res {
+		texts = append(texts, answer.Content)
+	}
+	return WordCloud{
+		QuestionID: questionID,
+		Responses:  len(res),
+		Words:      countWords(texts, stem),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTextAnswer(t *testing.T) {
+	text, err := validateTextAnswer("  Paris is lovely ")
+	require.NoError(t, err)
+	require.Equal(t, "Paris is lovely", text)
+
+	_, err = validateTextAnswer("   ")
+	require.Error(t, err)
+	_, err = validateTextAnswer(strings.Repeat("a", maxTextAnswerLength+1))
+	require.Error(t, err)
+	_, err = validateTextAnswer(strings.Repeat("é", maxTextAnswerLength))
+	require.NoError(t, err)
+}
+
+func TestCountWords(t *testing.T) {
+	texts := []string{
+		"The cat and THE dog",
+		"Cats, cats everywhere!",
+		"a dog's life",
+	}
+	require.Equal(t, []WordCount{
+		{Word: "dog", Count: 2},
+		{Word: "cat", Count: 1},
+		{Word: "cats", Count: 1},
+		{Word: "everywhere", Count: 1},
+		{Word: "life", Count: 1},
+	}, countWords(texts, false))
+
+	// with stemming "cat" and "cats" are the same word
+	require.Equal(t, []WordCount{
+		{Word: "cat", Count: 2},
+		{Word: "dog", Count: 2},
+		{Word: "everywhere", Count: 1},
+		{Word: "life", Count: 1},
+	}, countWords(texts, true))
+}
+
+func TestStemWord(t *testing.T) {
+	for word, stem := range map[string]string{
+		"running": "run",
+		"stopped": "stop",
+		"studies": "study",
+		"quickly": "quick",
+		"class":   "class",
+		"is":      "is",
+		"cats":    "cat",
+		"bus":     "bus",
+	} {
+		require.Equal(t, stem, stemWord(word), word)
+	}
+}
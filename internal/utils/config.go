@@ -17,6 +17,12 @@ type Config struct {
 	JwtSecretKey            string `mapstructure:"JWT_SECRET_KEY"`
 	AccessTokenExpiredTime  int32  `mapstructure:"ACCESS_TOKEN_EXPIRED_TIME"`
 	RefreshTokenExpiredTime int32  `mapstructure:"REFRESH_TOKEN_EXPIRED_TIME"`
+	SessionStore            string `mapstructure:"SESSION_STORE"`
+	Broadcaster             string `mapstructure:"BROADCASTER"`
+	MaxParticipants         int    `mapstructure:"MAX_PARTICIPANTS"`
+	// ChatBlocklist is a comma separated list of words masked or rejected in chat
+	ChatBlocklist string `mapstructure:"CHAT_BLOCKLIST"`
+	ChatFilter    string `mapstructure:"CHAT_FILTER"`
 
 	FBKey    string `mapstructure:"FB_KEY"`
 	FBSecret string `mapstructure:"FB_SECRET"`
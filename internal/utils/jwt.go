@@ -19,6 +19,17 @@ type jwtClaims struct {
 	Email  string
 }
 
+// participantAudience marks the tokens of live room participants so they are
+// never accepted as access tokens.
+const participantAudience = "participant"
+
+type participantClaims struct {
+	jwt.StandardClaims
+	SessionID     string
+	ParticipantID string
+	Username      string
+}
+
 func (w *JwtWrapper) GenerateToken(user entities.User, expirationHours int32) (signedToken string, err error) {
 	expiredAt := time.Now().Local().Add(time.Hour * time.Duration(expirationHours)).Unix()
 	claims := &jwtClaims{
@@ -60,6 +71,10 @@ func (w *JwtWrapper) ValidateToken(signedToken string) (claims *jwtClaims, err e
 		return nil, errors.New("couldn't parse claims")
 	}
 
+	if claims.Audience != "" {
+		return nil, errors.New("invalid token audience")
+	}
+
 	if claims.ExpiresAt < time.Now().Local().Unix() {
 		return nil, errors.New("JWT is expired")
 	}
@@ -67,3 +82,54 @@ func (w *JwtWrapper) ValidateToken(signedToken string) (claims *jwtClaims, err e
 	return claims, nil
 
 }
+
+// GenerateParticipantToken signs the identity of a guest in a presentation
+// session, the guest sends it back to take its place again after a disconnect.
+func (w *JwtWrapper) GenerateParticipantToken(sessionID, participantID, username string, expirationHours int32) (signedToken string, err error) {
+	expiredAt := time.Now().Local().Add(time.Hour * time.Duration(expirationHours)).Unix()
+	claims := &participantClaims{
+		SessionID:     sessionID,
+		ParticipantID: participantID,
+		Username:      username,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  participantAudience,
+			ExpiresAt: expiredAt,
+			Issuer:    w.Issuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString([]byte(w.SecretKey))
+}
+
+func (w *JwtWrapper) ValidateParticipantToken(signedToken string) (claims *participantClaims, err error) {
+	token, err := jwt.ParseWithClaims(
+		signedToken,
+		&participantClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			return []byte(w.SecretKey), nil
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	claims, ok := token.Claims.(*participantClaims)
+	if !ok {
+		return nil, errors.New("couldn't parse claims")
+	}
+
+	if !claims.VerifyAudience(participantAudience, true) {
+		return nil, errors.New("invalid token audience")
+	}
+
+	if claims.ExpiresAt < time.Now().Local().Unix() {
+		return nil, errors.New("JWT is expired")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vtv-us/kahoot-backend/internal/entities"
+)
+
+func TestParticipantToken(t *testing.T) {
+	w := JwtWrapper{SecretKey: RandomString(32), Issuer: "test"}
+	sessionID := RandomString(12)
+
+	token, err := w.GenerateParticipantToken(sessionID, "participant-id", "guest", 1)
+	require.NoError(t, err)
+
+	claims, err := w.ValidateParticipantToken(token)
+	require.NoError(t, err)
+	require.Equal(t, sessionID, claims.SessionID)
+	require.Equal(t, "participant-id", claims.ParticipantID)
+	require.Equal(t, "guest", claims.Username)
+
+	// a participant token is not an access token
+	_, err = w.ValidateToken(token)
+	require.Error(t, err)
+
+	other := JwtWrapper{SecretKey: RandomString(32), Issuer: "test"}
+	_, err = other.ValidateParticipantToken(token)
+	require.Error(t, err)
+}
+
+func TestAccessTokenIsNotParticipantToken(t *testing.T) {
+	w := JwtWrapper{SecretKey: RandomString(32), Issuer: "test"}
+
+	token, err := w.GenerateToken(entities.User{UserID: RandomString(12)}, 1)
+	require.NoError(t, err)
+
+	_, err = w.ValidateParticipantToken(token)
+	require.Error(t, err)
+}
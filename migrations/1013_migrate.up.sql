@@ -0,0 +1 @@
+alter table "question" add column "time_limit" integer not null default 0;
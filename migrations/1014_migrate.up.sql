@@ -0,0 +1,3 @@
+alter table "answer" add column "is_correct" boolean not null default false;
+
+alter table "answer_history" add column "points" integer not null default 0;
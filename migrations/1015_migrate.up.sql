@@ -0,0 +1,15 @@
+create table "live_room" (
+    "room_id" text not null,
+    "data" jsonb not null,
+    "updated_at" timestamptz not null default (now()),
+    constraint "live_room_pkey" primary key ("room_id")
+);
+
+create table "live_pin" (
+    "pin" text not null,
+    "room_id" text not null unique,
+    "created_at" timestamptz not null default (now()),
+    constraint "live_pin_pkey" primary key ("pin")
+);
+
+alter table "live_pin" add foreign key ("room_id") references "live_room" ("room_id") on delete cascade;
@@ -0,0 +1,8 @@
+create table "live_event" (
+    "id" bigserial not null,
+    "payload" jsonb not null,
+    "created_at" timestamptz not null default (now()),
+    constraint "live_event_pkey" primary key ("id")
+);
+
+create index on "live_event" ("created_at");
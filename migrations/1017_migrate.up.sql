@@ -0,0 +1,42 @@
+create table "presentation_session" (
+    "id" text not null,
+    "slide_id" text not null,
+    "host" text not null,
+    "group_id" text not null default '',
+    "started_at" timestamptz not null default (now()),
+    "ended_at" timestamptz,
+    constraint "presentation_session_pkey" primary key ("id")
+);
+
+create index on "presentation_session" ("slide_id", "started_at");
+
+-- results recorded before sessions existed are kept as one ended session per slide
+insert into "presentation_session" ("id", "slide_id", "host", "started_at", "ended_at")
+select 'legacy-' || "slide_id", "slide_id", '', min("created_at"), max("created_at")
+from (
+    select "slide_id", "created_at" from "answer_history"
+    union all
+    select "slide_id", "created_at" from "chat_msg"
+    union all
+    select "slide_id", "created_at" from "user_question"
+) as "legacy"
+group by "slide_id";
+
+-- answers are kept by participant, the user ID or the guest ID, the username is only shown
+alter table "answer_history" add column "session_id" text;
+alter table "answer_history" add column "participant" text;
+update "answer_history" set "session_id" = 'legacy-' || "slide_id", "participant" = 'name/' || "username";
+alter table "answer_history" alter column "session_id" set not null;
+alter table "answer_history" alter column "participant" set not null;
+alter table "answer_history" drop constraint "answer_history_pkey";
+alter table "answer_history" add constraint "answer_history_pkey" primary key ("session_id", "participant", "question_id");
+
+alter table "chat_msg" add column "session_id" text;
+update "chat_msg" set "session_id" = 'legacy-' || "slide_id";
+alter table "chat_msg" alter column "session_id" set not null;
+create index on "chat_msg" ("session_id", "created_at");
+
+alter table "user_question" add column "session_id" text;
+update "user_question" set "session_id" = 'legacy-' || "slide_id";
+alter table "user_question" alter column "session_id" set not null;
+create index on "user_question" ("session_id");
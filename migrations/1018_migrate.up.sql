@@ -0,0 +1,13 @@
+create table "participant_kick" (
+    "id" bigserial not null,
+    "session_id" text not null,
+    "username" text not null,
+    "user_id" text not null default '',
+    "participant_id" text not null default '',
+    "banned" boolean not null default false,
+    "kicked_by" text not null,
+    "created_at" timestamptz not null default (now()),
+    constraint "participant_kick_pkey" primary key ("id")
+);
+
+create index on "participant_kick" ("session_id");
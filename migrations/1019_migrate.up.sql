@@ -0,0 +1,8 @@
+create table "session_team_member" (
+    "session_id" text not null,
+    "username" text not null,
+    "team" text not null,
+    "created_at" timestamptz not null default (now()),
+    "participant" text not null,
+    constraint "session_team_member_pkey" primary key ("session_id", "participant")
+);
@@ -0,0 +1,11 @@
+create table "text_answer" (
+    "session_id" text not null,
+    "question_id" text not null,
+    "username" text not null,
+    "slide_id" text not null,
+    "content" text not null,
+    "created_at" timestamptz not null default (now()),
+    "updated_at" timestamptz not null default (now()),
+    "participant" text not null,
+    constraint "text_answer_pkey" primary key ("session_id", "question_id", "participant")
+);
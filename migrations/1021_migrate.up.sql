@@ -0,0 +1,14 @@
+create table "answer_selection" (
+    "session_id" text not null,
+    "username" text not null,
+    "slide_id" text not null,
+    "question_id" text not null,
+    "answer_id" text not null default '',
+    "position" integer not null default 0,
+    "value" integer not null default 0,
+    "created_at" timestamptz not null default (now()),
+    "participant" text not null,
+    constraint "answer_selection_pkey" primary key ("session_id", "question_id", "participant", "answer_id")
+);
+
+create index on "answer_selection" ("session_id", "question_id");
@@ -0,0 +1,6 @@
+create table "user_question_vote" (
+    "question_id" text not null,
+    "voter" text not null,
+    "created_at" timestamptz not null default (now()),
+    constraint "user_question_vote_pkey" primary key ("question_id", "voter")
+);
@@ -0,0 +1,2 @@
+alter table "user_question" add column "status" text not null default 'approved';
+alter table "user_question" add column "anonymous" boolean not null default false;
@@ -0,0 +1 @@
+alter table "chat_msg" add column "deleted" boolean not null default false;
@@ -0,0 +1,10 @@
+alter table "chat_msg" add column "parent_id" text not null default '';
+create index on "chat_msg" ("session_id", "parent_id", "created_at", "id");
+
+create table "chat_reaction" (
+    "msg_id" text not null,
+    "reactor" text not null,
+    "emoji" text not null,
+    "created_at" timestamptz not null default (now()),
+    constraint "chat_reaction_pkey" primary key ("msg_id", "reactor", "emoji")
+);
@@ -0,0 +1,7 @@
+create table "reaction_total" (
+    "session_id" text not null,
+    "question_id" text not null,
+    "emoji" text not null,
+    "count" integer not null default 0,
+    constraint "reaction_total_pkey" primary key ("session_id", "question_id", "emoji")
+);
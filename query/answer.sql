@@ -4,6 +4,7 @@ INSERT INTO "answer" (
     question_id,
     index,
     raw_answer,
+    is_correct,
     created_at,
     updated_at
 ) VALUES (
@@ -11,6 +12,7 @@ INSERT INTO "answer" (
     $2,
     $3,
     $4,
+    $5,
     now(),
     now()
 )
@@ -30,6 +32,7 @@ SELECT * FROM "answer" WHERE question_id = $1 AND index = $2;
 UPDATE "answer" SET
     index = $2,
     raw_answer = $3,
+    is_correct = $4,
     updated_at = now()
 WHERE id = $1
 RETURNING *;
@@ -0,0 +1,27 @@
+-- name: CreateAnswerSelection :exec
+INSERT INTO "answer_selection" (
+    session_id,
+    participant,
+    username,
+    slide_id,
+    question_id,
+    answer_id,
+    position,
+    value
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: DeleteAnswerSelections :exec
+DELETE FROM "answer_selection"
+WHERE session_id = $1 AND question_id = $2 AND participant = $3;
+
+-- name: ListAnswerSelectionsByQuestion :many
+SELECT * FROM "answer_selection"
+WHERE session_id = $1 AND question_id = $2
+ORDER BY participant, position;
+
+-- name: ListAnswerSelectionsBySession :many
+SELECT * FROM "answer_selection"
+WHERE session_id = $1
+ORDER BY question_id, participant, position;
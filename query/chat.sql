@@ -4,16 +4,49 @@ INSERT INTO "chat_msg" (
     slide_id,
     username,
     content,
+    session_id,
+    parent_id,
     created_at
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
+    $5,
+    $6,
     now()
 )
 RETURNING *;
 
 -- name: GetChatBySlide :many
 SELECT * FROM "chat_msg" WHERE slide_id = $1
-ORDER BY created_at ASC;
\ No newline at end of file
+ORDER BY created_at ASC;
+
+-- name: GetChatBySession :many
+SELECT * FROM "chat_msg" WHERE session_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeleteChatMsg :one
+UPDATE "chat_msg"
+SET deleted = true
+WHERE id = $1 AND session_id = $2
+RETURNING *;
+
+-- name: GetChatMsg :one
+SELECT * FROM "chat_msg" WHERE id = $1 AND session_id = $2;
+
+-- name: ListChatPage :many
+SELECT * FROM "chat_msg"
+WHERE session_id = $1 AND parent_id = ''
+AND (created_at, id) < (sqlc.arg(before_time)::timestamptz, sqlc.arg(before_id)::text)
+ORDER BY created_at DESC, id DESC
+LIMIT $4;
+
+-- name: ListChatReplies :many
+SELECT * FROM "chat_msg" WHERE session_id = $1 AND parent_id = $2
+ORDER BY created_at ASC, id ASC;
+
+-- name: CountChatReplies :many
+SELECT parent_id, count(*) AS count FROM "chat_msg"
+WHERE parent_id = ANY(sqlc.arg(msg_ids)::text[])
+GROUP BY parent_id;
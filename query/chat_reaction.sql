@@ -0,0 +1,22 @@
+-- name: CreateChatReaction :execrows
+INSERT INTO "chat_reaction" (
+    msg_id,
+    reactor,
+    emoji
+) VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChatReaction :execrows
+DELETE FROM "chat_reaction"
+WHERE msg_id = $1 AND reactor = $2 AND emoji = $3;
+
+-- name: CountChatReactions :many
+SELECT msg_id, emoji, count(*) AS count, bool_or(reactor = sqlc.arg(reactor)) AS reacted
+FROM "chat_reaction"
+WHERE msg_id = ANY(sqlc.arg(msg_ids)::text[])
+GROUP BY msg_id, emoji
+ORDER BY msg_id, min(created_at);
@@ -1,20 +1,24 @@
 -- name: UpsertAnswerHistory :one
 INSERT INTO "answer_history" (
+    "session_id",
+    "participant",
     "username",
     "slide_id",
     "question_id",
-    "answer_id"
+    "answer_id",
+    "points"
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6, $7
 ) ON CONFLICT ON CONSTRAINT "answer_history_pkey" DO UPDATE SET
-    "answer_id" = $4,
+    "answer_id" = $6,
+    "points" = $7,
     "updated_at" = now()
 RETURNING *;
 
 -- name: GetAnswerHistory :one
 SELECT *
 FROM "answer_history"
-WHERE username = $1 AND slide_id = $2 AND question_id = $3;
+WHERE session_id = $1 AND participant = $2 AND question_id = $3;
 
 -- name: ListAnswerHistoryBySlideID :many
 SELECT *
@@ -22,10 +26,16 @@ FROM "answer_history"
 WHERE slide_id = $1
 ORDER BY updated_at DESC;
 
+-- name: ListAnswerHistoryBySessionID :many
+SELECT *
+FROM "answer_history"
+WHERE session_id = $1
+ORDER BY updated_at DESC;
+
 -- name: ListAnswerHistoryByQuestionID :many
 SELECT *
 FROM "answer_history"
-WHERE question_id = $1
+WHERE session_id = $1 AND question_id = $2
 ORDER BY updated_at DESC;
 
 -- name: ListAnswerHistoryByAnswerID :many
@@ -37,6 +47,13 @@ ORDER BY updated_at DESC;
 -- name: CountAnswerByQuestionID :many
 SELECT slide_id, question_id, answer_id, count(*) as count
 FROM "answer_history"
-WHERE question_id = $1
+WHERE session_id = $1 AND question_id = $2
 GROUP BY slide_id, question_id, answer_id
 ORDER BY count DESC;
+
+-- name: GetLeaderboardBySessionID :many
+SELECT participant, CAST(MAX(username) AS text) AS username, CAST(SUM(points) AS integer) AS score
+FROM "answer_history"
+WHERE session_id = $1
+GROUP BY participant
+ORDER BY score DESC, username ASC;
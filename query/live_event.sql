@@ -0,0 +1,15 @@
+-- name: NotifyLiveEvent :exec
+SELECT pg_notify('live_event', sqlc.arg(payload)::text);
+
+-- name: CreateLiveEvent :one
+INSERT INTO "live_event" (
+    payload
+) VALUES (
+    $1
+) RETURNING id;
+
+-- name: GetLiveEvent :one
+SELECT * FROM "live_event" WHERE id = $1;
+
+-- name: DeleteLiveEventsBefore :exec
+DELETE FROM "live_event" WHERE created_at < $1;
@@ -0,0 +1,57 @@
+-- name: CreateLiveRoomIfNotExists :exec
+INSERT INTO "live_room" (
+    room_id,
+    data
+) VALUES (
+    $1, 'null'
+) ON CONFLICT (room_id) DO NOTHING;
+
+-- name: GetLiveRoom :one
+SELECT * FROM "live_room" WHERE room_id = $1;
+
+-- name: GetLiveRoomForUpdate :one
+SELECT * FROM "live_room" WHERE room_id = $1
+FOR UPDATE;
+
+-- name: UpdateLiveRoom :exec
+UPDATE "live_room" SET
+    data = $2,
+    updated_at = now()
+WHERE room_id = $1;
+
+-- name: DeleteLiveRoom :exec
+DELETE FROM "live_room" WHERE room_id = $1;
+
+-- name: ListActiveLiveRoomIDs :many
+SELECT room_id FROM "live_room"
+WHERE jsonb_typeof(data->'Participants') = 'array'
+AND jsonb_array_length(data->'Participants') > 0
+ORDER BY room_id;
+
+-- name: ListLiveRoomIDsBySID :many
+SELECT room_id FROM "live_room"
+WHERE data->'Participants' @> jsonb_build_array(jsonb_build_object('SID', sqlc.arg(sid)::text));
+
+-- name: GetLiveRoomIDByGroup :one
+SELECT room_id FROM "live_room"
+WHERE data->>'GroupID' = sqlc.arg(group_id)::text
+AND data->>'IsGroup' = 'true'
+ORDER BY updated_at DESC
+LIMIT 1;
+
+-- name: CreateLivePin :execrows
+INSERT INTO "live_pin" (
+    pin,
+    room_id
+) VALUES (
+    $1, $2
+) ON CONFLICT DO NOTHING;
+
+-- name: GetLivePin :one
+SELECT * FROM "live_pin" WHERE pin = $1;
+
+-- name: GetLivePinByRoom :one
+SELECT * FROM "live_pin" WHERE room_id = $1;
+
+-- name: DeleteLivePinByRoom :exec
+DELETE FROM "live_pin" WHERE room_id = $1;
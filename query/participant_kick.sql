@@ -0,0 +1,15 @@
+-- name: CreateParticipantKick :one
+INSERT INTO "participant_kick" (
+    session_id,
+    username,
+    user_id,
+    participant_id,
+    banned,
+    kicked_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListParticipantKicksBySession :many
+SELECT * FROM "participant_kick" WHERE session_id = $1
+ORDER BY created_at;
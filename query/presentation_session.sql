@@ -0,0 +1,21 @@
+-- name: CreatePresentationSession :one
+INSERT INTO "presentation_session" (
+    id,
+    slide_id,
+    host,
+    group_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetPresentationSession :one
+SELECT * FROM "presentation_session" WHERE id = $1;
+
+-- name: ListPresentationSessionsBySlide :many
+SELECT * FROM "presentation_session" WHERE slide_id = $1
+ORDER BY started_at DESC;
+
+-- name: EndPresentationSessionsBySlide :exec
+UPDATE "presentation_session" SET
+    ended_at = now()
+WHERE slide_id = $1 AND ended_at IS NULL;
@@ -7,6 +7,7 @@ INSERT INTO "question" (
     meta,
     long_description,
     type,
+    time_limit,
     created_at,
     updated_at
 ) VALUES (
@@ -17,6 +18,7 @@ INSERT INTO "question" (
     $5,
     $6,
     $7,
+    $8,
     now(),
     now()
 )
@@ -44,6 +46,7 @@ UPDATE "question" SET
     long_description = $4,
     index = $5,
     type = $6,
+    time_limit = $7,
     updated_at = now()
 WHERE id = $1
 RETURNING *;
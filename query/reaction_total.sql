@@ -0,0 +1,14 @@
+-- name: AddReactionTotal :exec
+INSERT INTO "reaction_total" (
+    session_id,
+    question_id,
+    emoji,
+    count
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (session_id, question_id, emoji) DO UPDATE SET
+    count = "reaction_total".count + EXCLUDED.count;
+
+-- name: ListReactionTotalsBySession :many
+SELECT * FROM "reaction_total" WHERE session_id = $1
+ORDER BY question_id, emoji;
@@ -0,0 +1,27 @@
+-- name: UpsertSessionTeamMember :exec
+INSERT INTO "session_team_member" (
+    session_id,
+    participant,
+    username,
+    team
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (session_id, participant) DO UPDATE SET
+    username = EXCLUDED.username,
+    team = EXCLUDED.team;
+
+-- name: DeleteSessionTeamMembersNotIn :exec
+DELETE FROM "session_team_member"
+WHERE session_id = sqlc.arg(session_id) AND NOT (team = ANY(sqlc.arg(teams)::text[]));
+
+-- name: ListSessionTeamMembers :many
+SELECT * FROM "session_team_member" WHERE session_id = $1
+ORDER BY team, username;
+
+-- name: GetTeamLeaderboardBySessionID :many
+SELECT m.team, CAST(COALESCE(SUM(a.points), 0) AS integer) AS score
+FROM "session_team_member" m
+LEFT JOIN "answer_history" a ON a.session_id = m.session_id AND a.participant = m.participant
+WHERE m.session_id = $1
+GROUP BY m.team
+ORDER BY score DESC, m.team ASC;
@@ -0,0 +1,22 @@
+-- name: UpsertTextAnswer :one
+INSERT INTO "text_answer" (
+    session_id,
+    question_id,
+    participant,
+    username,
+    slide_id,
+    content
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) ON CONFLICT (session_id, question_id, participant) DO UPDATE SET
+    content = EXCLUDED.content,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListTextAnswersByQuestion :many
+SELECT * FROM "text_answer" WHERE session_id = $1 AND question_id = $2
+ORDER BY created_at ASC;
+
+-- name: ListTextAnswersBySession :many
+SELECT * FROM "text_answer" WHERE session_id = $1
+ORDER BY question_id, created_at ASC;
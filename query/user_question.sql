@@ -4,13 +4,19 @@ INSERT INTO "user_question" (
   "slide_id",
   "username",
   "content",
+  "session_id",
+  "status",
+  "anonymous",
   "created_at"
 ) VALUES (
-    $1, $2, $3, $4, now()
+    $1, $2, $3, $4, $5, $6, $7, now()
 ) ON CONFLICT (question_id) DO UPDATE SET
     "slide_id" = $2,
     "username" = $3,
-    "content" = $4
+    "content" = $4,
+    "session_id" = $5,
+    "status" = $6,
+    "anonymous" = $7
 RETURNING *;
 
 -- name: GetUserQuestion :one
@@ -24,9 +30,29 @@ FROM "user_question"
 WHERE slide_id = $1
 ORDER BY created_at DESC;
 
--- name: UpvoteUserQuestion :one
+-- name: ListUserQuestionBySession :many
+SELECT *
+FROM "user_question"
+WHERE session_id = $1
+ORDER BY created_at DESC;
+
+-- name: UpdateUserQuestionStatus :one
+UPDATE "user_question"
+SET status = $2
+WHERE question_id = $1
+RETURNING *;
+
+-- name: UpdateUserQuestionContent :one
+UPDATE "user_question"
+SET content = $2
+WHERE question_id = $1
+RETURNING *;
+
+-- name: UpdateUserQuestionVotes :one
 UPDATE "user_question"
-SET votes = votes + 1
+SET votes = (
+    SELECT count(*) FROM "user_question_vote" v WHERE v.question_id = $1
+)
 WHERE question_id = $1
 RETURNING *;
 
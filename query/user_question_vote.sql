@@ -0,0 +1,17 @@
+-- name: CreateUserQuestionVote :execrows
+INSERT INTO "user_question_vote" (
+    question_id,
+    voter
+) VALUES (
+    $1, $2
+) ON CONFLICT DO NOTHING;
+
+-- name: DeleteUserQuestionVote :execrows
+DELETE FROM "user_question_vote"
+WHERE question_id = $1 AND voter = $2;
+
+-- name: ListVotedUserQuestionIDs :many
+SELECT v.question_id
+FROM "user_question_vote" v
+JOIN "user_question" q ON q.question_id = v.question_id
+WHERE q.session_id = $1 AND v.voter = $2;